@@ -0,0 +1,69 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+// applyPatch applies the patch to each file, writing the result next to the source with the given suffix
+// (overwriting the source if the suffix is empty).
+// With dryRun, only the per-form summary of the changes is printed.
+func applyPatch(ctx context.Context, w io.Writer, converter Converter, patch transform.Patch, files []string, suffix string, dryRun bool) error {
+	for _, fn := range files {
+		root, err := readForm(ctx, converter, fn)
+		if err != nil {
+			return err
+		}
+		changes, err := patch.Apply(root)
+		if err != nil {
+			return fmt.Errorf("apply to %q: %w", fn, err)
+		}
+		var n int
+		for _, c := range changes {
+			n += len(c.Paths)
+		}
+		fmt.Fprintf(w, "%s: %d changes\n", fn, n)
+		for _, c := range changes {
+			fmt.Fprintf(w, "  %-7s %4d  %s\n", c.Op, len(c.Paths), c.Query)
+			if dryRun {
+				for _, p := range c.Paths {
+					fmt.Fprintf(w, "\t%s\n", p)
+				}
+			}
+		}
+		if dryRun || n == 0 {
+			continue
+		}
+		dst := fn
+		if suffix != "" {
+			ext := filepath.Ext(fn)
+			dst = strings.TrimSuffix(fn, ext) + suffix + ext
+		}
+		log.Printf("Write %q.", dst)
+		if err = writeForm(ctx, converter, dst, root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
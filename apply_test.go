@@ -0,0 +1,89 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/UNO-SOFT/forms2xml/jdapitest"
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+// fakeConverter converts with the fake fmb encoding of jdapitest. With failWrite,
+// the conversion to fmb writes a part of the module, then fails.
+type fakeConverter struct{ failWrite bool }
+
+func (c fakeConverter) Convert(ctx context.Context, w io.Writer, r io.Reader, mimeType string) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if mimeType == "application/xml" {
+		if b, err = jdapitest.EncodeFMB(b); err == nil && c.failWrite {
+			w.Write(b[:len(b)/2])
+			return errors.New("conversion failed")
+		}
+	} else {
+		b, err = jdapitest.DecodeFMB(b)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func (c fakeConverter) ConvertFiles(ctx context.Context, dst, src string) error {
+	return errors.ErrUnsupported
+}
+
+func TestApplyFailed(t *testing.T) {
+	dir := t.TempDir()
+	fn, _ := testModule(t, dir, "a")
+	want, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, err := transform.ReadPatch(strings.NewReader(`[{"select": "//Block", "set": {"Comment": "x"}}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = applyPatch(context.Background(), io.Discard, fakeConverter{failWrite: true}, patch, []string{fn}, "", false); err == nil {
+		t.Fatal("no error")
+	}
+	// the source is intact, and no temporary file is left behind
+	if got, err := os.ReadFile(fn); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(got, want) {
+		t.Errorf("source changed:\n%s", got)
+	}
+	if des, _ := os.ReadDir(dir); len(des) != 1 {
+		t.Errorf("got %v", des)
+	}
+
+	if err = applyPatch(context.Background(), io.Discard, fakeConverter{}, patch, []string{fn}, "", false); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFMB(t, fn); !bytes.Contains(got, []byte(`Comment="x"`)) {
+		t.Errorf("not patched:\n%s", got)
+	}
+}
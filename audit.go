@@ -0,0 +1,248 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+// auditCodeAttrs are the PL/SQL code attributes by element.
+var auditCodeAttrs = map[string]string{
+	"Trigger":     "TriggerText",
+	"ProgramUnit": "ProgramUnitText",
+	"MenuItem":    "MenuItemCode",
+}
+
+// auditCheck is a security relevant construct.
+type auditCheck struct {
+	Category string
+	Pattern  *regexp.Regexp
+}
+
+var auditChecks = []auditCheck{
+	{"FORMS_DDL", regexp.MustCompile(`(?i)\bFORMS_DDL\s*\(`)},
+	{"EXEC_SQL", regexp.MustCompile(`(?i)\bEXEC_SQL\s*\.\s*\w+`)},
+	{"EXECUTE IMMEDIATE", regexp.MustCompile(`(?i)\bEXECUTE\s+IMMEDIATE\b`)},
+	{"DBMS_SQL", regexp.MustCompile(`(?i)\bDBMS_SQL\s*\.\s*\w+`)},
+	{"HOST", regexp.MustCompile(`(?i)\bHOST\s*\(`)},
+	{"TEXT_IO", regexp.MustCompile(`(?i)\b(?:CLIENT_)?TEXT_IO\s*\.\s*FOPEN\b`)},
+}
+
+var (
+	// rSetBlockWhere matches the SET_BLOCK_PROPERTY calls setting a WHERE clause, the 1st group is the value.
+	rSetBlockWhere = regexp.MustCompile(`(?is)\bSET_BLOCK_PROPERTY\s*\([^,;]*,\s*(?:DEFAULT_WHERE|ONETIME_WHERE)\s*,([^;]*)\)\s*;`)
+	rPLSQLIdent    = regexp.MustCompile(`^[A-Za-z][\w$#.]*$`)
+	// rPLSQLAssign matches the assignments, the 1st group is the variable, the 2nd the value.
+	rPLSQLAssign = regexp.MustCompile(`(?s)([A-Za-z][\w$#.]*)\s*:=([^;]*)`)
+)
+
+// qQuoteEnd are the closing delimiters of the q-quoted literals (q'[...]'), if not the opening one.
+var qQuoteEnd = map[byte]byte{'[': ']', '{': '}', '(': ')', '<': '>'}
+
+// blankPLSQL replaces the comments and the contents of the string literals
+// (also the q-quoted q'[...]' ones) with spaces, keeping the offsets and the line breaks.
+func blankPLSQL(code string) string {
+	b := []byte(code)
+	blank := func(i, j int) {
+		for ; i < j; i++ {
+			if b[i] != '\n' {
+				b[i] = ' '
+			}
+		}
+	}
+	for i := 0; i < len(b); i++ {
+		switch {
+		case (b[i] == 'q' || b[i] == 'Q') && i+2 < len(b) && b[i+1] == '\'' && !afterIdent(code, i):
+			end, ok := qQuoteEnd[b[i+2]]
+			if !ok {
+				end = b[i+2]
+			}
+			j := strings.Index(code[i+3:], string(end)+"'")
+			if j < 0 {
+				j = len(b) - (i + 3)
+			}
+			blank(i+3, i+3+j)
+			i += 3 + j + 1
+		case b[i] == '\'':
+			j := i + 1
+			for j < len(b) && (b[j] != '\'' || j+1 < len(b) && b[j+1] == '\'') {
+				if b[j] == '\'' {
+					j++ // ''
+				}
+				j++
+			}
+			blank(i+1, j)
+			i = j
+		case b[i] == '"': // quoted identifier
+			if j := strings.IndexByte(code[i+1:], '"'); j >= 0 {
+				i += j + 1
+			}
+		case b[i] == '-' && i+1 < len(b) && b[i+1] == '-':
+			j := strings.IndexByte(code[i:], '\n')
+			if j < 0 {
+				j = len(b) - i
+			}
+			blank(i, i+j)
+			i += j
+		case b[i] == '/' && i+1 < len(b) && b[i+1] == '*':
+			j := strings.Index(code[i+2:], "*/")
+			if j < 0 {
+				j = len(b) - i
+			} else {
+				j += 4
+			}
+			blank(i, i+j)
+			i += j - 1
+		}
+	}
+	return string(b)
+}
+
+// afterIdent reports whether code[i] continues an identifier, not counting the N of nq'...'.
+func afterIdent(code string, i int) bool {
+	if i > 0 && (code[i-1] == 'n' || code[i-1] == 'N') {
+		i--
+	}
+	if i == 0 {
+		return false
+	}
+	c := code[i-1]
+	return c == '_' || c == '$' || c == '#' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+type auditFinding struct {
+	File     string `json:"file"`
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Category string `json:"category"`
+	Snippet  string `json:"snippet"`
+}
+
+type auditMatch struct {
+	Category string
+	Offset   int
+}
+
+// auditCode returns the findings of the code, ordered by offset.
+// The comments and the string literals are not searched.
+func auditCode(code string) []auditMatch {
+	code = blankPLSQL(code)
+	var found []auditMatch
+	for _, c := range auditChecks {
+		for _, loc := range c.Pattern.FindAllStringIndex(code, -1) {
+			if loc[0] > 0 && strings.ContainsRune(".$#_", rune(code[loc[0]-1])) {
+				continue // a qualified name (pkg.host) or part of an identifier (my_host)
+			}
+			found = append(found, auditMatch{Category: c.Category, Offset: loc[0]})
+		}
+	}
+	// the variables built by concatenation
+	concat := make(map[string]bool)
+	for _, m := range rPLSQLAssign.FindAllStringSubmatch(code, -1) {
+		if strings.Contains(m[2], "||") {
+			concat[strings.ToUpper(m[1])] = true
+		}
+	}
+	for _, loc := range rSetBlockWhere.FindAllStringSubmatchIndex(code, -1) {
+		value := strings.TrimSpace(code[loc[2]:loc[3]])
+		if !strings.Contains(value, "||") && !(rPLSQLIdent.MatchString(value) && concat[strings.ToUpper(value)]) {
+			continue
+		}
+		found = append(found, auditMatch{Category: "DEFAULT_WHERE concatenation", Offset: loc[0]})
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Offset < found[j].Offset })
+	return found
+}
+
+// auditModule collects the findings of the triggers and program units of the module.
+func auditModule(fn string, module *transform.Node) []auditFinding {
+	var findings []auditFinding
+	module.Walk(func(n *transform.Node) error {
+		k, ok := auditCodeAttrs[n.Name]
+		if !ok {
+			return nil
+		}
+		code := transform.Text(n.Get(k))
+		if code == "" {
+			return nil
+		}
+		for _, m := range auditCode(code) {
+			off := m.Offset
+			line := strings.Count(code[:off], "\n") + 1
+			start := strings.LastIndexByte(code[:off], '\n') + 1
+			end := strings.IndexByte(code[off:], '\n')
+			if end < 0 {
+				end = len(code)
+			} else {
+				end += off
+			}
+			snippet := strings.TrimSpace(code[start:end])
+			if r := []rune(snippet); len(r) > 120 {
+				snippet = string(r[:117]) + "..."
+			}
+			findings = append(findings, auditFinding{File: fn, Path: n.Path(), Line: line,
+				Category: m.Category, Snippet: snippet})
+		}
+		return nil
+	})
+	return findings
+}
+
+// auditFiles lists the security relevant code of each form to w, in the given format (table, csv or json).
+func auditFiles(ctx context.Context, w io.Writer, converter Converter, format string, files []string) error {
+	var findings []auditFinding
+	for _, fn := range files {
+		root, err := readForm(ctx, converter, fn)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, auditModule(fn, root.Module())...)
+	}
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if findings == nil {
+			findings = []auditFinding{}
+		}
+		return enc.Encode(findings)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"FILE", "PATH", "LINE", "CATEGORY", "SNIPPET"})
+		for _, f := range findings {
+			cw.Write([]string{f.File, f.Path, strconv.Itoa(f.Line), f.Category, f.Snippet})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tPATH\tLINE\tCATEGORY\tSNIPPET")
+	for _, f := range findings {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", f.File, f.Path, f.Line, f.Category, f.Snippet)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBlankPLSQL(t *testing.T) {
+	for _, tc := range []struct{ In, Want string }{
+		{"x := 'a--b'; -- c\ny;", "x := '    ';     \ny;"},
+		{"/* a\nb */ host('it''s')", "    \n     host('     ')"},
+		{`"A--B" := 1; /* open`, `"A--B" := 1;        `},
+		{"'open\nx", "'    \n "},
+		{"x := q'[it's -- ]' || Q'{a}'; y;", "x := q'[        ]' || Q'{ }'; y;"},
+		{"nq'!a'b!' -- c\nq'<open", "nq'!   !'     \nq'<    "},
+		{"seq'a'", "seq' '"},
+	} {
+		if got := blankPLSQL(tc.In); got != tc.Want {
+			t.Errorf("%q: got %q, wanted %q", tc.In, got, tc.Want)
+		}
+	}
+}
+
+func TestAuditCode(t *testing.T) {
+	for _, tc := range []struct {
+		Code string
+		Want []string
+	}{
+		{Code: "FORMS_DDL('drop table x');", Want: []string{"FORMS_DDL"}},
+		{Code: "n := exec_sql.open_connection(c);", Want: []string{"EXEC_SQL"}},
+		{Code: "execute  immediate v_sql;", Want: []string{"EXECUTE IMMEDIATE"}},
+		{Code: "c := DBMS_SQL.OPEN_CURSOR;", Want: []string{"DBMS_SQL"}},
+		{Code: "Host('rm -rf ' || v_dir);", Want: []string{"HOST"}},
+		{Code: "f := client_text_io.fopen(fn, 'w');\ng := text_io.fopen(fn, 'r');", Want: []string{"TEXT_IO", "TEXT_IO"}},
+		{Code: "SET_BLOCK_PROPERTY('EMP', DEFAULT_WHERE, 'ename = ''' || :ctrl.name || '''');",
+			Want: []string{"DEFAULT_WHERE concatenation"}},
+		{Code: "v_where := 'deptno = ' || :ctrl.deptno;\nset_block_property('EMP', onetime_where, v_where);",
+			Want: []string{"DEFAULT_WHERE concatenation"}},
+		// not a finding
+		{Code: "v_where := 'deptno = :ctrl.deptno';\nset_block_property('EMP', default_where, v_where);"},
+		{Code: "SET_BLOCK_PROPERTY('EMP', DEFAULT_WHERE, 'a = 1, b = 2; -- || x');"},
+		{Code: "my_host(x); pkg.host(y); v_forms_ddl(1);"},
+		{Code: "-- host('x');\n/* execute immediate\nv; */ null;"},
+		{Code: "message('Do not call HOST( here'); v := 'execute immediate';"},
+		{Code: "message(q'[Don't call HOST( here]'); v := q'{it's execute immediate}';"},
+	} {
+		var got []string
+		for _, m := range auditCode(tc.Code) {
+			got = append(got, m.Category)
+		}
+		if d := cmp.Diff(tc.Want, got); d != "" {
+			t.Errorf("%q: %s", tc.Code, d)
+		}
+	}
+}
+
+func TestAuditFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := writeTestForms(t, dir, map[string]string{"emp": `<?xml version="1.0" encoding="UTF-8"?>
+<Module version="101020002" xmlns="http://xmlns.oracle.com/Forms">
+  <FormModule Name="EMP">
+    <Block Name="EMP">
+      <Trigger Name="WHEN-BUTTON-PRESSED" TriggerText="-- run it&amp;#10;begin&amp;#10;  host('ls');&amp;#10;end;"/>
+    </Block>
+    <ProgramUnit Name="P" ProgramUnitText="procedure p is begin forms_ddl(v); end;"/>
+  </FormModule>
+</Module>`})
+	var buf bytes.Buffer
+	if err := auditFiles(context.Background(), &buf, nil, "json", files); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	var got []auditFinding
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []auditFinding{
+		{File: files[0], Path: "FormModule[EMP]/Block[EMP]/Trigger[WHEN-BUTTON-PRESSED]", Line: 3, Category: "HOST", Snippet: "host('ls');"},
+		{File: files[0], Path: "FormModule[EMP]/ProgramUnit[P]", Line: 1, Category: "FORMS_DDL", Snippet: "procedure p is begin forms_ddl(v); end;"},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Error(d)
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+// dbColumn is a row of ALL_TAB_COLUMNS.
+type dbColumn struct {
+	Owner    string `json:"OWNER"`
+	Table    string `json:"TABLE_NAME"`
+	Column   string `json:"COLUMN_NAME"`
+	DataType string `json:"DATA_TYPE"`
+	Length   int    `json:"DATA_LENGTH"`
+	Nullable string `json:"NULLABLE"`
+}
+
+// dbSchema is the offline schema description: table -> column -> column info.
+// Tables are registered both with and without the owner prefix.
+type dbSchema map[string]map[string]dbColumn
+
+// readDBSchema reads the JSON (array of objects) or CSV (with header) export of ALL_TAB_COLUMNS.
+func readDBSchema(fn string) (dbSchema, error) {
+	fh, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	var cols []dbColumn
+	if strings.HasSuffix(strings.ToLower(fn), ".json") {
+		var rows []map[string]any
+		if err = json.NewDecoder(fh).Decode(&rows); err != nil {
+			return nil, fmt.Errorf("parse %q: %w", fn, err)
+		}
+		for _, row := range rows {
+			rec := make(map[string]string, len(row))
+			for k, v := range row {
+				if v != nil {
+					rec[strings.ToUpper(k)] = fmt.Sprintf("%v", v)
+				}
+			}
+			cols = append(cols, newDBColumn(rec))
+		}
+	} else {
+		cr := csv.NewReader(fh)
+		cr.FieldsPerRecord = -1
+		head, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("read header of %q: %w", fn, err)
+		}
+		for i, h := range head {
+			head[i] = strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		}
+		for {
+			row, err := cr.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("read %q: %w", fn, err)
+			}
+			rec := make(map[string]string, len(head))
+			for i, v := range row {
+				if i < len(head) {
+					rec[head[i]] = v
+				}
+			}
+			cols = append(cols, newDBColumn(rec))
+		}
+	}
+	schema := make(dbSchema)
+	for _, c := range cols {
+		if c.Table == "" || c.Column == "" {
+			continue
+		}
+		for _, t := range []string{c.Table, c.Owner + "." + c.Table} {
+			if strings.HasPrefix(t, ".") {
+				continue
+			}
+			m := schema[t]
+			if m == nil {
+				m = make(map[string]dbColumn)
+				schema[t] = m
+			}
+			m[c.Column] = c
+		}
+	}
+	if len(schema) == 0 {
+		return nil, fmt.Errorf("%q: no columns found", fn)
+	}
+	return schema, nil
+}
+
+func newDBColumn(rec map[string]string) dbColumn {
+	length, _ := strconv.Atoi(rec["DATA_LENGTH"])
+	if cl, _ := strconv.Atoi(rec["CHAR_LENGTH"]); cl > 0 {
+		length = cl
+	}
+	return dbColumn{
+		Owner: strings.ToUpper(rec["OWNER"]), Table: strings.ToUpper(rec["TABLE_NAME"]),
+		Column: strings.ToUpper(rec["COLUMN_NAME"]), DataType: strings.ToUpper(rec["DATA_TYPE"]),
+		Length: length, Nullable: rec["NULLABLE"],
+	}
+}
+
+func (s dbSchema) table(name string) map[string]dbColumn {
+	return s[strings.ToUpper(strings.Trim(name, `"`))]
+}
+
+type dbProblem struct {
+	Path, Message string
+}
+
+// checkDB checks the module's data sources, columns and LOV queries against the schema.
+func (s dbSchema) checkDB(module *transform.Node) []dbProblem {
+	var probs []dbProblem
+	add := func(n *transform.Node, format string, args ...any) {
+		probs = append(probs, dbProblem{Path: n.Path(), Message: fmt.Sprintf(format, args...)})
+	}
+	for _, b := range module.Find("Block") {
+		table := b.Get("QueryDataSourceName")
+		if table == "" || (b.Get("QueryDataSourceType") != "" && b.Get("QueryDataSourceType") != "Table") {
+			continue
+		}
+		cols := s.table(table)
+		if cols == nil {
+			add(b, "table %s does not exist", table)
+			continue
+		}
+		for _, c := range b.Children {
+			switch c.Name {
+			case "DataSourceColumn":
+				name := strings.ToUpper(c.Get("DSCName"))
+				col, ok := cols[name]
+				if !ok {
+					add(c, "column %s.%s does not exist", table, name)
+					continue
+				}
+				if t := c.Get("DSCType"); t != "" && !dbTypeCompatible(t, col.DataType) {
+					add(c, "type %s mismatch: %s.%s is %s", t, table, name, col.DataType)
+				}
+				if l := atoi(c.Get("DSCLength")); l > 0 && col.Length > 0 && isCharType(col.DataType) && l != col.Length {
+					add(c, "length %d mismatch: %s.%s is %d", l, table, name, col.Length)
+				}
+			case "Item":
+				if c.Get("DatabaseItem") == "false" {
+					continue
+				}
+				switch c.Get("ItemType") {
+				case "Push Button", "Button", "Image", "Chart Item", "Bean Area":
+					continue
+				}
+				name := strings.ToUpper(firstNonEmpty(c.Get("ColumnName"), c.Get("Name")))
+				col, ok := cols[name]
+				if !ok {
+					add(c, "column %s.%s does not exist", table, name)
+					continue
+				}
+				if t := c.Get("DataType"); t != "" && !dbTypeCompatible(t, col.DataType) {
+					add(c, "type %s mismatch: %s.%s is %s", t, table, name, col.DataType)
+				}
+				if l := atoi(c.Get("MaximumLength")); l > 0 && col.Length > 0 && isCharType(col.DataType) && l > col.Length {
+					add(c, "length %d exceeds %s.%s (%d)", l, table, name, col.Length)
+				}
+			}
+		}
+	}
+	for _, rg := range module.Find("RecordGroup") {
+		q := transform.Text(rg.Get("RecordGroupQuery"))
+		if q == "" {
+			continue
+		}
+		for _, msg := range s.checkQuery(q) {
+			add(rg, "%s", msg)
+		}
+	}
+	return probs
+}
+
+var (
+	rSelectFrom = regexp.MustCompile(`(?is)^\s*select\s+(?:distinct\s+)?(.*?)\s+from\s+(.*?)(?:\s+where\s|\s+order\s+by\s|\s+group\s+by\s|\s+connect\s+by\s|\s+start\s+with\s|$)`)
+	rIdent      = regexp.MustCompile(`^(?:([A-Za-z][A-Za-z0-9_$#]*)\.)?([A-Za-z][A-Za-z0-9_$#]*)$`)
+	rAlias      = regexp.MustCompile(`(?i)\s+(?:as\s+)?"?[A-Za-z][A-Za-z0-9_$#]*"?$`)
+)
+
+// checkQuery checks the simple column references of a (record group) SELECT against its FROM tables.
+// Expressions, subqueries and joins with unknown tables are skipped.
+func (s dbSchema) checkQuery(q string) []string {
+	m := rSelectFrom.FindStringSubmatch(q)
+	if m == nil || strings.Contains(m[2], "(") {
+		return nil
+	}
+	tables := make(map[string]map[string]dbColumn)
+	var names []string
+	for _, t := range strings.Split(m[2], ",") {
+		f := strings.Fields(t)
+		if len(f) == 0 {
+			continue
+		}
+		cols := s.table(f[0])
+		if cols == nil {
+			return []string{fmt.Sprintf("table %s does not exist", f[0])}
+		}
+		names = append(names, strings.ToUpper(f[0]))
+		tables[strings.ToUpper(f[0])] = cols
+		if len(f) > 1 {
+			tables[strings.ToUpper(f[len(f)-1])] = cols
+		}
+	}
+	var msgs []string
+	for _, expr := range strings.Split(m[1], ",") {
+		expr = strings.TrimSpace(rAlias.ReplaceAllString(strings.TrimSpace(expr), ""))
+		im := rIdent.FindStringSubmatch(expr)
+		if im == nil {
+			continue
+		}
+		col := strings.ToUpper(im[2])
+		switch col {
+		case "ROWID", "ROWNUM", "NULL", "SYSDATE", "USER", "LEVEL":
+			continue
+		}
+		if im[1] != "" {
+			if cols := tables[strings.ToUpper(im[1])]; cols != nil {
+				if _, ok := cols[col]; !ok {
+					msgs = append(msgs, fmt.Sprintf("column %s.%s does not exist", im[1], col))
+				}
+			}
+			continue
+		}
+		var found bool
+		for _, cols := range tables {
+			if _, found = cols[col]; found {
+				break
+			}
+		}
+		if !found {
+			msgs = append(msgs, fmt.Sprintf("column %s does not exist in %s", col, strings.Join(names, ", ")))
+		}
+	}
+	return msgs
+}
+
+func isCharType(t string) bool {
+	switch t {
+	case "CHAR", "NCHAR", "VARCHAR2", "NVARCHAR2", "VARCHAR":
+		return true
+	}
+	return false
+}
+
+// dbTypeCompatible reports whether the Forms (item DataType or DSCType) type fits the database type.
+func dbTypeCompatible(formsType, dbType string) bool {
+	ft := strings.ToUpper(formsType)
+	if i := strings.IndexByte(ft, '('); i >= 0 {
+		ft = ft[:i]
+	}
+	if ft == dbType || (strings.HasPrefix(dbType, ft) && strings.HasPrefix(dbType, "TIMESTAMP")) {
+		return true
+	}
+	isNum := func(t string) bool {
+		switch t {
+		case "NUMBER", "FLOAT", "INTEGER", "BINARY_FLOAT", "BINARY_DOUBLE":
+			return true
+		}
+		return false
+	}
+	isDate := func(t string) bool { return t == "DATE" || strings.HasPrefix(t, "TIMESTAMP") }
+	switch ft {
+	case "CHAR", "ALPHA", "VARCHAR2", "VARCHAR", "NVARCHAR2", "NCHAR":
+		return isCharType(dbType) || dbType == "CLOB" || dbType == "NCLOB" || dbType == "ROWID"
+	case "LONG":
+		return dbType == "LONG" || dbType == "CLOB" || isCharType(dbType)
+	case "NUMBER", "INTEGER", "INT", "RNUMBER", "RINT", "MONEY", "RMONEY":
+		return isNum(dbType)
+	case "DATE", "DATETIME", "EDATE", "JDATE", "TIMESTAMP":
+		return isDate(dbType)
+	}
+	return true
+}
+
+// checkDBFiles checks each form against the schema, printing the problems to w.
+func checkDBFiles(ctx context.Context, w io.Writer, converter Converter, schema dbSchema, files []string) error {
+	var n int
+	for _, fn := range files {
+		root, err := readForm(ctx, converter, fn)
+		if err != nil {
+			return err
+		}
+		for _, p := range schema.checkDB(root.Module()) {
+			fmt.Fprintf(w, "%s: %s: %s\n", fn, p.Path, p.Message)
+			n++
+		}
+	}
+	if n != 0 {
+		return fmt.Errorf("found %d problems", n)
+	}
+	return nil
+}
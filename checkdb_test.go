@@ -0,0 +1,180 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const checkDBColumns = "\ufeffowner,table_name,column_name,data_type,data_length,char_length,nullable\n" +
+	"SCOTT,EMP,EMPNO,NUMBER,22,0,N\n" +
+	"SCOTT,EMP,ENAME,VARCHAR2,40,10,Y\n" +
+	"SCOTT,EMP,HIREDATE,DATE,7,0,Y\n" +
+	"SCOTT,EMP,DEPTNO,NUMBER,22,0,Y\n" +
+	"SCOTT,DEPT,DEPTNO,NUMBER,22,0,N\n" +
+	"SCOTT,DEPT,DNAME,VARCHAR2,14,14,Y\n"
+
+const checkDBXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Module version="101020002" xmlns="http://xmlns.oracle.com/Forms">
+  <FormModule Name="EMP">
+    <Block Name="EMP" QueryDataSourceName="scott.emp">
+      <Item Name="EMPNO" ItemType="Text Item" DataType="Number"/>
+      <Item Name="ENAME" ItemType="Text Item" DataType="Char" MaximumLength="20"/>
+      <Item Name="HIRED" ItemType="Text Item" DataType="Char" ColumnName="HIREDATE"/>
+      <Item Name="SAL" ItemType="Text Item" DataType="Number"/>
+      <Item Name="DUMMY" ItemType="Text Item" DatabaseItem="false"/>
+      <Item Name="OK" ItemType="Push Button"/>
+      <DataSourceColumn DSCName="ENAME" DSCType="VARCHAR2" DSCLength="10"/>
+      <DataSourceColumn DSCName="DEPTNO" DSCType="VARCHAR2"/>
+    </Block>
+    <Block Name="BONUS" QueryDataSourceName="BONUS"/>
+    <Block Name="V" QueryDataSourceName="select 1 from dual" QueryDataSourceType="FROM clause query"/>
+    <Block Name="CTRL"/>
+    <RecordGroup Name="RG_DEPT" RecordGroupQuery="select d.deptno, dname as name, d.loc&amp;#10;from dept d order by 1"/>
+  </FormModule>
+</Module>`
+
+func TestReadDBSchema(t *testing.T) {
+	dir := t.TempDir()
+	csvFn, jsonFn := filepath.Join(dir, "cols.csv"), filepath.Join(dir, "cols.json")
+	if err := os.WriteFile(csvFn, []byte(checkDBColumns), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jsonFn, []byte(`[
+{"OWNER":"SCOTT","TABLE_NAME":"EMP","COLUMN_NAME":"ENAME","DATA_TYPE":"VARCHAR2","DATA_LENGTH":40,"CHAR_LENGTH":10,"NULLABLE":"Y"},
+{"owner":null,"table_name":"dual","column_name":"dummy","data_type":"VARCHAR2","data_length":1}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		File, Table, Column string
+		Want                dbColumn
+	}{
+		{File: csvFn, Table: "EMP", Column: "ENAME",
+			Want: dbColumn{Owner: "SCOTT", Table: "EMP", Column: "ENAME", DataType: "VARCHAR2", Length: 10, Nullable: "Y"}},
+		{File: csvFn, Table: "scott.dept", Column: "DEPTNO",
+			Want: dbColumn{Owner: "SCOTT", Table: "DEPT", Column: "DEPTNO", DataType: "NUMBER", Length: 22, Nullable: "N"}},
+		{File: jsonFn, Table: `"SCOTT.EMP"`, Column: "ENAME",
+			Want: dbColumn{Owner: "SCOTT", Table: "EMP", Column: "ENAME", DataType: "VARCHAR2", Length: 10, Nullable: "Y"}},
+		{File: jsonFn, Table: "DUAL", Column: "DUMMY",
+			Want: dbColumn{Table: "DUAL", Column: "DUMMY", DataType: "VARCHAR2", Length: 1}},
+	} {
+		schema, err := readDBSchema(tc.File)
+		if err != nil {
+			t.Fatalf("%s: %+v", tc.File, err)
+		}
+		if d := cmp.Diff(tc.Want, schema.table(tc.Table)[tc.Column]); d != "" {
+			t.Errorf("%s %s.%s: %s", filepath.Base(tc.File), tc.Table, tc.Column, d)
+		}
+	}
+	empty := filepath.Join(dir, "empty.csv")
+	if err := os.WriteFile(empty, []byte("OWNER,TABLE_NAME\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readDBSchema(empty); err == nil {
+		t.Error("empty schema: no error")
+	}
+}
+
+func TestCheckDB(t *testing.T) {
+	dir := t.TempDir()
+	schemaFn := filepath.Join(dir, "cols.csv")
+	if err := os.WriteFile(schemaFn, []byte(checkDBColumns), 0644); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := readDBSchema(schemaFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := writeTestForms(t, dir, map[string]string{"emp": checkDBXML})
+	var buf bytes.Buffer
+	if err = checkDBFiles(context.Background(), &buf, nil, schema, files); err == nil || err.Error() != "found 6 problems" {
+		t.Errorf("got %v", err)
+	}
+	want := []string{
+		"FormModule[EMP]/Block[EMP]/Item[ENAME]: length 20 exceeds scott.emp.ENAME (10)",
+		"FormModule[EMP]/Block[EMP]/Item[HIRED]: type Char mismatch: scott.emp.HIREDATE is DATE",
+		"FormModule[EMP]/Block[EMP]/Item[SAL]: column scott.emp.SAL does not exist",
+		"FormModule[EMP]/Block[EMP]/DataSourceColumn: type VARCHAR2 mismatch: scott.emp.DEPTNO is NUMBER",
+		"FormModule[EMP]/Block[BONUS]: table BONUS does not exist",
+		"FormModule[EMP]/RecordGroup[RG_DEPT]: column d.LOC does not exist",
+	}
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		got = append(got, strings.TrimPrefix(line, files[0]+": "))
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Error(d)
+	}
+}
+
+func TestCheckQuery(t *testing.T) {
+	schema := dbSchema{
+		"EMP":  {"EMPNO": {}, "ENAME": {}, "DEPTNO": {}},
+		"DEPT": {"DEPTNO": {}, "DNAME": {}},
+	}
+	for _, tc := range []struct {
+		Query string
+		Want  []string
+	}{
+		{Query: "SELECT ename, empno FROM emp WHERE deptno = 10"},
+		{Query: "select distinct e.ename \"Name\", d.dname from emp e, dept d where e.deptno = d.deptno"},
+		{Query: "select rowid, null, sysdate from emp"},
+		{Query: "select ename, sal from emp", Want: []string{"column SAL does not exist in EMP"}},
+		{Query: "select e.job from emp e order by 1", Want: []string{"column e.JOB does not exist"}},
+		{Query: "select x from bonus", Want: []string{"table bonus does not exist"}},
+		// the expressions, the unknown aliases and the subqueries are not checked
+		{Query: "select nvl(comm, 0), x.y, upper(ename) from emp"},
+		{Query: "select a from (select 1 a from dual)"},
+		{Query: "update emp set sal = 0"},
+	} {
+		if d := cmp.Diff(tc.Want, schema.checkQuery(tc.Query)); d != "" {
+			t.Errorf("%q: %s", tc.Query, d)
+		}
+	}
+}
+
+func TestDBTypeCompatible(t *testing.T) {
+	for _, tc := range []struct {
+		Forms, DB string
+		Want      bool
+	}{
+		{"Char", "VARCHAR2", true},
+		{"VARCHAR2(30)", "VARCHAR2", true},
+		{"Char", "CLOB", true},
+		{"Char", "NUMBER", false},
+		{"Number", "NUMBER", true},
+		{"Integer", "FLOAT", true},
+		{"Number", "VARCHAR2", false},
+		{"Date", "DATE", true},
+		{"Datetime", "TIMESTAMP(6)", true},
+		{"Date", "NUMBER", false},
+		{"TIMESTAMP", "TIMESTAMP(6) WITH TIME ZONE", true},
+		{"Long", "CLOB", true},
+		{"Long", "NUMBER", false},
+		{"Object", "BLOB", true},
+	} {
+		if got := dbTypeCompatible(tc.Forms, tc.DB); got != tc.Want {
+			t.Errorf("%s - %s: got %t, wanted %t", tc.Forms, tc.DB, got, tc.Want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Command fakejdapi is a stand-in for the Java JDAPI helper, see jdapitest:
+//
+//	forms2xml --jdapi-helper=fakejdapi 6to11 a.fmb
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/UNO-SOFT/forms2xml/jdapitest"
+)
+
+func main() {
+	addr := ":8000"
+	if len(os.Args) > 1 {
+		addr = os.Args[len(os.Args)-1]
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if err := jdapitest.ListenAndServe(ctx, addr); err != nil {
+		log.Fatalf("%+v", err)
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+type codegenForm struct {
+	Name, File, Package string
+	Blocks              []codegenBlock
+}
+
+type codegenBlock struct {
+	Name, GoName, Table string
+	Where, OrderBy      string
+	Columns             []codegenColumn
+}
+
+type codegenColumn struct {
+	Item, Column, GoName, GoType string
+	DataType                     string
+	Length                       int
+	Required, PrimaryKey         bool
+}
+
+// Keys returns the primary key columns of the block.
+func (b codegenBlock) Keys() []codegenColumn {
+	var keys []codegenColumn
+	for _, c := range b.Columns {
+		if c.PrimaryKey {
+			keys = append(keys, c)
+		}
+	}
+	return keys
+}
+
+// NonKeys returns the columns which are not part of the primary key.
+func (b codegenBlock) NonKeys() []codegenColumn {
+	var cols []codegenColumn
+	for _, c := range b.Columns {
+		if !c.PrimaryKey {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+// Updatable reports whether the block has both key and non-key columns, to UPDATE the non-keys by the keys.
+func (b codegenBlock) Updatable() bool {
+	return len(b.Keys()) != 0 && len(b.NonKeys()) != 0
+}
+
+// Imports returns the packages used by the Go types of the columns.
+func (f codegenForm) Imports() []string {
+	var usesSQL, usesTime bool
+	for _, b := range f.Blocks {
+		for _, c := range b.Columns {
+			usesSQL = usesSQL || strings.HasPrefix(c.GoType, "sql.")
+			usesTime = usesTime || c.GoType == "time.Time"
+		}
+	}
+	var imports []string
+	if usesSQL {
+		imports = append(imports, "database/sql")
+	}
+	if usesTime {
+		imports = append(imports, "time")
+	}
+	return imports
+}
+
+// newCodegenForm collects the base-table blocks of the module.
+// The Go names of the blocks are prefixed with the form's name, as the forms share the package.
+func newCodegenForm(fn, pkg string, module *transform.Node) codegenForm {
+	form := codegenForm{Name: module.Get("Name"), File: fn, Package: pkg}
+	if form.Name == "" {
+		form.Name = strings.TrimSuffix(filepath.Base(fn), filepath.Ext(fn))
+	}
+	for _, b := range module.Find("Block") {
+		table := b.Get("QueryDataSourceName")
+		if table == "" || (b.Get("QueryDataSourceType") != "" && b.Get("QueryDataSourceType") != "Table") {
+			continue
+		}
+		dscs := make(map[string]*transform.Node)
+		for _, c := range b.Find("DataSourceColumn") {
+			dscs[strings.ToUpper(c.Get("DSCName"))] = c
+		}
+		block := codegenBlock{Name: b.Get("Name"), GoName: goName(form.Name) + goName(b.Get("Name")), Table: table,
+			Where: transform.Text(b.Get("WhereClause")), OrderBy: transform.Text(b.Get("OrderByClause"))}
+		for _, it := range b.Children {
+			if it.Name != "Item" || it.Get("DatabaseItem") == "false" {
+				continue
+			}
+			switch it.Get("ItemType") {
+			case "Push Button", "Button", "Image", "Chart Item", "Bean Area":
+				continue
+			}
+			col := codegenColumn{Item: it.Get("Name"), Column: firstNonEmpty(it.Get("ColumnName"), it.Get("Name")),
+				DataType: it.Get("DataType"), Length: atoi(it.Get("MaximumLength")),
+				Required: it.Get("Required") == "true", PrimaryKey: it.Get("PrimaryKey") == "true"}
+			if dsc := dscs[strings.ToUpper(col.Column)]; dsc != nil {
+				if col.DataType == "" {
+					col.DataType = dsc.Get("DSCType")
+				}
+				if col.Length == 0 {
+					col.Length = atoi(dsc.Get("DSCLength"))
+				}
+				if dsc.Get("DSCMandatory") == "true" {
+					col.Required = true
+				}
+			}
+			col.GoName, col.GoType = goName(col.Item), goType(col.DataType, col.Required)
+			block.Columns = append(block.Columns, col)
+		}
+		if len(block.Columns) != 0 {
+			form.Blocks = append(form.Blocks, block)
+		}
+	}
+	return form
+}
+
+// goName converts the SNAKE_CASE name to CamelCase.
+func goName(s string) string {
+	var buf strings.Builder
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return !(unicode.IsLetter(r) || unicode.IsDigit(r)) }) {
+		part = strings.ToLower(part)
+		r, size := utf8.DecodeRuneInString(part)
+		buf.WriteRune(unicode.ToUpper(r))
+		buf.WriteString(part[size:])
+	}
+	if r, _ := utf8.DecodeRuneInString(buf.String()); buf.Len() == 0 || unicode.IsDigit(r) {
+		return "X" + buf.String()
+	}
+	return buf.String()
+}
+
+func goType(dataType string, required bool) string {
+	switch strings.ToUpper(dataType) {
+	case "NUMBER", "MONEY", "RNUMBER":
+		if required {
+			return "float64"
+		}
+		return "sql.NullFloat64"
+	case "INTEGER", "INT", "RINT":
+		if required {
+			return "int64"
+		}
+		return "sql.NullInt64"
+	case "DATE", "DATETIME", "EDATE", "JDATE":
+		if required {
+			return "time.Time"
+		}
+		return "sql.NullTime"
+	}
+	if required {
+		return "string"
+	}
+	return "sql.NullString"
+}
+
+var codegenFuncs = template.FuncMap{
+	"join": strings.Join,
+	"cols": func(cols []codegenColumn, format, sep string) string {
+		ss := make([]string, len(cols))
+		for i, c := range cols {
+			ss[i] = strings.NewReplacer("{col}", c.Column, "{item}", c.Item).Replace(format)
+		}
+		return strings.Join(ss, sep)
+	},
+}
+
+// DefaultCodegenTemplates are the "go" and "sql" templates, overridable with the same {{define}}s.
+// The UPDATE is generated only for the Updatable blocks.
+const DefaultCodegenTemplates = `{{define "go"}}// Code generated by forms2xml codegen from {{.File}}. DO NOT EDIT.
+
+package {{.Package}}
+
+{{with .Imports}}
+import ({{range .}}
+	"{{.}}"{{end}}
+)
+{{end}}
+{{range .Blocks}}
+// {{.GoName}} is a record of the {{.Name}} block ({{.Table}}).
+type {{.GoName}} struct {
+{{range .Columns}}	{{.GoName}} {{.GoType}} ` + "`db:\"{{.Column}}\"`" + `{{if .DataType}} // {{.DataType}}{{if .Length}}({{.Length}}){{end}}{{end}}
+{{end}}}
+
+const (
+	// Select{{.GoName}} queries the {{.Name}} block.
+	Select{{.GoName}} = ` + "`{{template \"select\" .}}`" + `
+	// Insert{{.GoName}} inserts a {{.Name}} record.
+	Insert{{.GoName}} = ` + "`{{template \"insert\" .}}`" + `{{if .Updatable}}
+	// Update{{.GoName}} updates a {{.Name}} record.
+	Update{{.GoName}} = ` + "`{{template \"update\" .}}`" + `{{end}}
+)
+{{end}}{{end}}
+
+{{define "sql"}}-- Generated by forms2xml codegen from {{.File}}.
+{{range .Blocks}}
+-- {{.Name}}
+{{template "select" .}};
+{{template "insert" .}};
+{{if .Updatable}}{{template "update" .}};
+{{end}}{{end}}{{end}}
+
+{{define "select"}}SELECT {{cols .Columns "{col}" ", "}} FROM {{.Table}}{{with .Where}} WHERE {{.}}{{end}}{{with .OrderBy}} ORDER BY {{.}}{{end}}{{end}}
+{{define "insert"}}INSERT INTO {{.Table}} ({{cols .Columns "{col}" ", "}}) VALUES ({{cols .Columns ":{item}" ", "}}){{end}}
+{{define "update"}}UPDATE {{.Table}} SET {{cols .NonKeys "{col} = :{item}" ", "}} WHERE {{cols .Keys "{col} = :{item}" " AND "}}{{end}}
+`
+
+// codegenFiles generates <form>.go and <form>.sql into dstDir for each form.
+// The templates are read from tmplFiles, if given, overriding the defaults.
+func codegenFiles(ctx context.Context, converter Converter, dstDir, pkg string, tmplFiles []string, files []string) error {
+	tmpl, err := template.New("codegen").Funcs(codegenFuncs).Parse(DefaultCodegenTemplates)
+	if err != nil {
+		return err
+	}
+	if len(tmplFiles) != 0 {
+		if tmpl, err = tmpl.ParseFiles(tmplFiles...); err != nil {
+			return err
+		}
+	}
+	if err = os.MkdirAll(dstDir, 0755); err != nil {
+		return err
+	}
+	for _, fn := range files {
+		root, err := readForm(ctx, converter, fn)
+		if err != nil {
+			return err
+		}
+		form := newCodegenForm(fn, pkg, root.Module())
+		base := filepath.Join(dstDir, strings.ToLower(form.Name))
+		log.Printf("Generate %q from %q.", base+".{go,sql}", fn)
+		var buf bytes.Buffer
+		if err = tmpl.ExecuteTemplate(&buf, "go", form); err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+		b, err := format.Source(buf.Bytes())
+		if err != nil {
+			return fmt.Errorf("format %s: %w\n%s", fn, err, buf.String())
+		}
+		if err = os.WriteFile(base+".go", b, 0644); err != nil {
+			return err
+		}
+		buf.Reset()
+		if err = tmpl.ExecuteTemplate(&buf, "sql", form); err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+		if err = os.WriteFile(base+".sql", buf.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const codegenXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Module version="101020002" xmlns="http://xmlns.oracle.com/Forms">
+  <FormModule Name="%s">
+    <Block Name="EMP" QueryDataSourceName="EMP" WhereClause="deptno = 10" OrderByClause="ename">
+      <Item Name="EMPNO" ItemType="Text Item" DataType="Number" MaximumLength="4" PrimaryKey="true" Required="true"/>
+      <Item Name="ENAME" ItemType="Text Item" DataType="Char" MaximumLength="10"/>
+      <Item Name="HIREDATE" ItemType="Text Item" DataType="Date" ColumnName="HIRE_DATE"/>
+      <Item Name="OK" ItemType="Push Button"/>
+      <Item Name="DUMMY" ItemType="Text Item" DatabaseItem="false"/>
+      <DataSourceColumn DSCName="ENAME" DSCType="VARCHAR2" DSCLength="10" DSCMandatory="true"/>
+    </Block>
+    <Block Name="CTRL"><Item Name="X" ItemType="Text Item"/></Block>
+  </FormModule>
+</Module>`
+
+// codegenKeysXML has a block without a primary key, and one with only key columns, without sql or time types.
+const codegenKeysXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Module version="101020002" xmlns="http://xmlns.oracle.com/Forms">
+  <FormModule Name="DEPT">
+    <Block Name="DEPT" QueryDataSourceName="DEPT">
+      <Item Name="DNAME" ItemType="Text Item" DataType="Char" Required="true"/>
+    </Block>
+    <Block Name="ÉRTÉK" QueryDataSourceName="ERTEK">
+      <Item Name="KÓD" ItemType="Text Item" DataType="Char" PrimaryKey="true" Required="true"/>
+    </Block>
+  </FormModule>
+</Module>`
+
+// writeTestForms writes the XML forms (name -> content) into dir.
+func writeTestForms(t *testing.T, dir string, forms map[string]string) []string {
+	t.Helper()
+	var files []string
+	for name, x := range forms {
+		fn := filepath.Join(dir, name+".xml")
+		if err := os.WriteFile(fn, []byte(x), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, fn)
+	}
+	return files
+}
+
+func TestCodegen(t *testing.T) {
+	dir := t.TempDir()
+	files := writeTestForms(t, dir, map[string]string{
+		"emp": fmt.Sprintf(codegenXML, "EMP"), "emp_hist": fmt.Sprintf(codegenXML, "EMP_HIST")})
+	files = append(files, writeTestForms(t, dir, map[string]string{"dept": codegenKeysXML})...)
+	dst := filepath.Join(dir, "forms")
+	if err := codegenFiles(context.Background(), nil, dst, "forms", nil, files); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	// the forms share the package: it must compile, without unused imports
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	for _, name := range []string{"emp", "emp_hist", "dept"} {
+		f, err := parser.ParseFile(fset, filepath.Join(dst, name+".go"), nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, f)
+	}
+	pkg, err := (&types.Config{Importer: importer.Default()}).Check("forms", fset, parsed, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	typ, ok := pkg.Scope().Lookup("EmpEmp").(*types.TypeName)
+	if !ok {
+		t.Fatalf("no EmpEmp type in %v", pkg.Scope().Names())
+	}
+	st := typ.Type().Underlying().(*types.Struct)
+	var fields []string
+	for i := range st.NumFields() {
+		fields = append(fields, st.Field(i).Name()+" "+st.Field(i).Type().String())
+	}
+	// OK is a button, DUMMY is not a database item; ENAME is mandatory in the table
+	if got, want := strings.Join(fields, ", "), "Empno float64, Ename string, Hiredate database/sql.NullTime"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+	for _, name := range []string{"EmpHistEmp", "SelectEmpHistEmp", "InsertEmpEmp", "UpdateEmpEmp"} {
+		if pkg.Scope().Lookup(name) == nil {
+			t.Errorf("no %s", name)
+		}
+	}
+	if pkg.Scope().Lookup("EmpCtrl") != nil {
+		t.Error("CTRL has no base table")
+	}
+	if pkg.Scope().Lookup("DeptÉrték") == nil {
+		t.Errorf("no DeptÉrték in %v", pkg.Scope().Names())
+	}
+	// DEPT has no key, ÉRTÉK has only keys
+	for _, name := range []string{"UpdateDeptDept", "UpdateDeptÉrték"} {
+		if pkg.Scope().Lookup(name) != nil {
+			t.Errorf("%s is generated", name)
+		}
+	}
+
+	b, err := os.ReadFile(filepath.Join(dst, "emp.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"SELECT EMPNO, ENAME, HIRE_DATE FROM EMP WHERE deptno = 10 ORDER BY ename;",
+		"INSERT INTO EMP (EMPNO, ENAME, HIRE_DATE) VALUES (:EMPNO, :ENAME, :HIREDATE);",
+		"UPDATE EMP SET ENAME = :ENAME, HIRE_DATE = :HIREDATE WHERE EMPNO = :EMPNO;",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("%q is missing from\n%s", want, b)
+		}
+	}
+	if b, err = os.ReadFile(filepath.Join(dst, "dept.sql")); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "UPDATE") {
+		t.Errorf("UPDATE without key or non-key columns:\n%s", b)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for in, want := range map[string]string{
+		"EMP_NO": "EmpNo", "ÉRTÉK": "Érték", "ŐSZ_ÁR": "ŐszÁr", "1ST": "X1st", "__": "X",
+	} {
+		if got := goName(in); got != want {
+			t.Errorf("%q: got %q, wanted %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// ErrorKind is the class of a failed conversion.
+type ErrorKind int
+
+const (
+	ErrUnknown ErrorKind = iota
+	// ErrLibraryNotFound is an attached library missing from FORMS_PATH.
+	ErrLibraryNotFound
+	// ErrSubclassMissing is the missing source module of subclassed objects.
+	ErrSubclassMissing
+	// ErrDBConnect is the failed database connection of the helper.
+	ErrDBConnect
+	// ErrInvalidXML is a malformed or invalid XML module.
+	ErrInvalidXML
+	// ErrCrash is the helper dying (or JDAPI failing internally).
+	ErrCrash
+	// ErrTimeout is the helper not answering in time.
+	ErrTimeout
+)
+
+// The exit codes and retryability of the kinds: the transient ones may succeed with another helper.
+var errorKinds = [...]struct {
+	name      string
+	exitCode  int
+	retryable bool
+}{
+	ErrUnknown:         {"conversion failed", 1, false},
+	ErrLibraryNotFound: {"library not found in FORMS_PATH", 10, false},
+	ErrSubclassMissing: {"subclass source missing", 11, false},
+	ErrDBConnect:       {"database connection failed", 12, true},
+	ErrInvalidXML:      {"invalid XML", 13, false},
+	ErrCrash:           {"JDAPI crash", 14, true},
+	ErrTimeout:         {"timeout", 15, true},
+}
+
+func (k ErrorKind) String() string { return errorKinds[k].name }
+
+// ExitCode of the process failing with this kind of error.
+func (k ErrorKind) ExitCode() int { return errorKinds[k].exitCode }
+
+// Retryable reports whether the error is transient.
+func (k ErrorKind) Retryable() bool { return errorKinds[k].retryable }
+
+// The patterns of the kinds, tried in order on the error message and the stack trace.
+var errorPatterns = []struct {
+	kind ErrorKind
+	re   *regexp.Regexp
+}{
+	{ErrInvalidXML, regexp.MustCompile(`(?i)XMLParseException|SAXParseException|oracle\.xml\.parser|invalid XML|XML syntax error`)},
+	{ErrLibraryNotFound, regexp.MustCompile(`(?i)FRM-10102|FRM-10083|cannot (attach|find|open) (PL/SQL )?library|library .* not found`)},
+	{ErrSubclassMissing, regexp.MustCompile(`(?i)FRM-18108|FRM-18122|failed to load the following objects|subclass.* (not found|missing)`)},
+	{ErrDBConnect, regexp.MustCompile(`(?i)ORA-(01017|03113|03114|12154|12170|12505|12514|12541|28000)|TNS:|(cannot|could not|unable to|failed to) connect`)},
+	{ErrTimeout, regexp.MustCompile(`(?i)SocketTimeoutException|not ready after|deadline exceeded|timed? ?out`)},
+	{ErrCrash, regexp.MustCompile(`(?i)OutOfMemoryError|InternalError|StackOverflowError|fatal error has been detected|SIGSEGV|hs_err_pid|exited before it was ready|connection reset|broken pipe|unexpected EOF`)},
+}
+
+// classify returns the kind of the error message.
+func classify(texts ...string) ErrorKind {
+	for _, p := range errorPatterns {
+		for _, s := range texts {
+			if p.re.MatchString(s) {
+				return p.kind
+			}
+		}
+	}
+	return ErrUnknown
+}
+
+// ConvertError is a classified conversion error.
+type ConvertError struct {
+	Kind ErrorKind
+	// Message is the exception ("ERROR: " stripped).
+	Message string
+	// Stack is the stack trace (stderr) of the helper, if any.
+	Stack string
+	Err   error
+}
+
+func (e *ConvertError) Error() string {
+	msg := e.Kind.String()
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	if e.Stack != "" {
+		msg += "\n" + e.Stack
+	}
+	return msg
+}
+
+func (e *ConvertError) Unwrap() error { return e.Err }
+
+// newConvertError returns the classified error of the message (an "ERROR: exception" body)
+// and the stack trace.
+func newConvertError(msg, stack string, err error) *ConvertError {
+	msg = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(msg), "ERROR: "))
+	texts := []string{msg, stack}
+	if err != nil {
+		texts = append(texts, err.Error())
+	}
+	return &ConvertError{Kind: classify(texts...), Message: msg, Stack: strings.TrimSpace(stack), Err: err}
+}
+
+// responseError returns the error of the failed response, closing its body.
+func responseError(resp *http.Response, stack string) *ConvertError {
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	ce := newConvertError(string(b), stack, errors.New(resp.Status))
+	if ce.Message == "" && resp.StatusCode == http.StatusGatewayTimeout {
+		ce.Kind = ErrTimeout
+	}
+	return ce
+}
+
+// requestError classifies the error of a request: ctx done is a timeout,
+// a broken connection is a crash of the helper.
+func requestError(ctx context.Context, err error, stack string) *ConvertError {
+	var ce *ConvertError
+	if errors.As(err, &ce) {
+		return ce
+	}
+	ce = newConvertError("", stack, err)
+	if ctx.Err() != nil {
+		ce.Kind = ErrTimeout
+	} else if ce.Kind == ErrUnknown {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			ce.Kind = ErrCrash
+		}
+	}
+	return ce
+}
+
+// isRetryable reports whether the conversion may be retried: the error is transient.
+func isRetryable(err error) bool {
+	var ce *ConvertError
+	return errors.As(err, &ce) && ce.Kind.Retryable()
+}
+
+// exitCodesHelp lists the exit codes of the failed conversions.
+func exitCodesHelp() string {
+	var buf strings.Builder
+	buf.WriteString("Exit codes:\n")
+	for _, k := range errorKinds {
+		fmt.Fprintf(&buf, "\t%d\t%s\n", k.exitCode, k.name)
+	}
+	return buf.String()
+}
+
+// exitCode of the error.
+func exitCode(err error) int {
+	var ce *ConvertError
+	if errors.As(err, &ce) {
+		return ce.Kind.ExitCode()
+	}
+	return 1
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	for _, tc := range []struct {
+		msg, stack string
+		want       ErrorKind
+	}{
+		{"ERROR: oracle.forms.jdapi.JdapiException: FRM-10102: Cannot attach PL/SQL library BR_LIB", "", ErrLibraryNotFound},
+		{"ERROR: java.lang.Exception: library BR_FLIB.pll not found", "", ErrLibraryNotFound},
+		{"ERROR: oracle.forms.jdapi.JdapiException: FRM-18108: Failed to load the following objects.", "", ErrSubclassMissing},
+		{"", "oracle.forms.jdapi.JdapiException: ORA-12154: TNS:could not resolve the connect identifier", ErrDBConnect},
+		{"ERROR: oracle.xml.parser.v2.XMLParseException: Start of root element expected.", "", ErrInvalidXML},
+		{"ERROR: invalid XML: XML syntax error on line 1: unexpected EOF", "", ErrInvalidXML},
+		{"", "# A fatal error has been detected by the Java Runtime Environment:\n#  SIGSEGV (0xb)", ErrCrash},
+		{"ERROR: java.lang.OutOfMemoryError: Java heap space", "", ErrCrash},
+		{"ERROR: java.net.SocketTimeoutException: Read timed out", "", ErrTimeout},
+		{"ERROR: java.lang.NullPointerException", "\tat oracle.forms.util.xmltools.Forms2XML.dumpModule", ErrUnknown},
+		{"ERROR: java.io.EOFException", "\tat unosoft.forms.Serve.readModule", ErrUnknown},
+		{"ERROR: ORA-06502: PL/SQL: numeric or value error (FILE_EOF)", "", ErrUnknown},
+	} {
+		ce := newConvertError(tc.msg, tc.stack, nil)
+		if ce.Kind != tc.want {
+			t.Errorf("%q/%q: got %v, wanted %v", tc.msg, tc.stack, ce.Kind, tc.want)
+		}
+		if strings.HasPrefix(ce.Message, "ERROR:") {
+			t.Errorf("%q: got message %q", tc.msg, ce.Message)
+		}
+	}
+	if err := error(&ConvertError{Kind: ErrDBConnect}); !isRetryable(err) || exitCode(err) != 12 {
+		t.Errorf("%v: retryable=%t exitCode=%d", err, isRetryable(err), exitCode(err))
+	}
+	// a broken connection to the helper is a crash
+	for _, err := range []error{io.ErrUnexpectedEOF, fmt.Errorf("read: %w", io.EOF)} {
+		if ce := requestError(context.Background(), err, ""); ce.Kind != ErrCrash {
+			t.Errorf("%v: got %v", err, ce.Kind)
+		}
+	}
+	if err := errors.New("x"); isRetryable(err) || exitCode(err) != 1 {
+		t.Errorf("%v: retryable=%t exitCode=%d", err, isRetryable(err), exitCode(err))
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	jr := newJavaRunner(ctx, "", "", "", javaConfig{Helper: []string{os.Args[0]}}, 3, 2)
+
+	t.Run("invalid", func(t *testing.T) {
+		err := jr.Convert(ctx, io.Discard, strings.NewReader("<Module><FormModule>"), "application/xml")
+		var ce *ConvertError
+		if !errors.As(err, &ce) || ce.Kind != ErrInvalidXML {
+			t.Fatalf("got %#v", err)
+		}
+		// not retried, and the helper is kept
+		if st := jr.PoolStats(); st.Started != 1 || st.Failed != 0 || len(st.Workers) != 1 {
+			t.Errorf("got %+v", st)
+		}
+
+		// through serve, too
+		srv := httptest.NewServer(jr)
+		defer srv.Close()
+		err = mustRemote(t, ctx, srv.URL).Convert(ctx, io.Discard, strings.NewReader("<Module><FormModule>"), "application/xml")
+		if !errors.As(err, &ce) || ce.Kind != ErrInvalidXML {
+			t.Fatalf("remote: got %#v", err)
+		}
+	})
+
+	t.Run("crash", func(t *testing.T) {
+		ctx, jr := newTestPool(t, javaConfig{Env: map[string]string{fakeDelayEnv: "1s"}})
+		jr.MaxRetries = 3
+		if err := jr.Ready(ctx); err != nil {
+			t.Fatal(err)
+		}
+		pid := jr.PoolStats().Workers[0].PID
+		time.AfterFunc(300*time.Millisecond, func() { syscall.Kill(pid, syscall.SIGKILL) })
+		src, want := testModule(t, t.TempDir(), "a")
+		dst := filepath.Join(t.TempDir(), "a.xml")
+		// retried with a new helper
+		if err := jr.ConvertFiles(ctx, dst, src); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if got, err := os.ReadFile(dst); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(got, want) {
+			t.Errorf("got\n%s\nwanted\n%s", got, want)
+		}
+		if st := jr.PoolStats(); st.Started != 2 {
+			t.Errorf("got %+v", st)
+		}
+	})
+}
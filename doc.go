@@ -0,0 +1,320 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+// docFiles generates the HTML documentation of each form into dstDir/<form>.html, with a searchable index.html.
+func docFiles(ctx context.Context, converter Converter, dstDir string, files []string) error {
+	if err := os.MkdirAll(dstDir, 0755); err != nil {
+		return err
+	}
+	var forms []docForm
+	for _, fn := range files {
+		root, err := readForm(ctx, converter, fn)
+		if err != nil {
+			return err
+		}
+		forms = append(forms, newDocForm(fn, root.Module()))
+	}
+	known := make(map[string]bool, len(forms))
+	for _, f := range forms {
+		known[strings.ToUpper(f.Name)] = true
+	}
+	for _, f := range forms {
+		f.known = known
+		dst := filepath.Join(dstDir, f.Name+".html")
+		log.Printf("Document %q into %q.", f.File, dst)
+		if err := writeFile(dst, func(w io.Writer) error { return docFormTmpl.Execute(w, f) }); err != nil {
+			return err
+		}
+	}
+	return writeFile(filepath.Join(dstDir, "index.html"), func(w io.Writer) error {
+		return docIndexTmpl.Execute(w, forms)
+	})
+}
+
+type docForm struct {
+	Name, File string
+	Module     *transform.Node
+	Objects    []docObject
+	known      map[string]bool
+}
+
+type docObject struct {
+	Type, Name, Anchor string
+}
+
+func newDocForm(fn string, module *transform.Node) docForm {
+	f := docForm{Name: module.Get("Name"), File: fn, Module: module}
+	if f.Name == "" {
+		f.Name = strings.TrimSuffix(filepath.Base(fn), filepath.Ext(fn))
+	}
+	module.Walk(func(n *transform.Node) error {
+		if n == module || n.Get("Name") == "" {
+			return nil
+		}
+		f.Objects = append(f.Objects, docObject{Type: n.Name, Name: docName(n), Anchor: docAnchor(n)})
+		return nil
+	})
+	return f
+}
+
+// docName returns the qualified name (BLOCK.ITEM) of the node.
+func docName(n *transform.Node) string {
+	nm := n.Get("Name")
+	if p := n.Parent; p != nil && p.Name != "FormModule" && p.Get("Name") != "" {
+		nm = docName(p) + "." + nm
+	}
+	return nm
+}
+
+func docAnchor(n *transform.Node) string { return n.Name + "-" + docName(n) }
+
+// docParentName returns the qualified name of the subclass parent, as docName names it in its form:
+// the source objects of the parent (ParentSourceLevel1ObjectName, ...), or else the same as n's.
+func docParentName(n *transform.Node) string {
+	var parts []string
+	for _, k := range []string{"ParentSourceLevel1ObjectName", "ParentSourceLevel2ObjectName"} {
+		if v := n.Get(k); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	if p := n.Parent; len(parts) == 0 && p != nil && p.Name != "FormModule" && p.Get("Name") != "" {
+		parts = append(parts, docName(p))
+	}
+	return strings.Join(append(parts, n.Get("ParentName")), ".")
+}
+
+// Attrs returns the attributes of the node, sorted, without the ones shown separately.
+func (f docForm) Attrs(n *transform.Node, skip ...string) [][2]string {
+	kv := make([][2]string, 0, len(n.Attr))
+	for _, a := range n.Attr {
+		k := a.Name.Local
+		if k == "Name" || k == "xmlns" || strings.HasSuffix(k, "Text") || strings.HasSuffix(k, "Query") {
+			continue
+		}
+		for _, s := range skip {
+			if k == s {
+				k = ""
+				break
+			}
+		}
+		if k != "" {
+			kv = append(kv, [2]string{k, transform.Text(a.Value)})
+		}
+	}
+	sort.Slice(kv, func(i, j int) bool { return kv[i][0] < kv[j][0] })
+	return kv
+}
+
+// Parent returns the link to the subclass parent of the node.
+func (f docForm) Parent(n *transform.Node) template.HTML {
+	pm := n.Get("ParentModule")
+	if pm == "" {
+		return ""
+	}
+	parent := docParentName(n)
+	s := html.EscapeString(pm + "." + parent)
+	if f.known[strings.ToUpper(pm)] {
+		s = fmt.Sprintf(`<a href="%s.html#%s">%s</a>`,
+			html.EscapeString(pm), html.EscapeString(n.Name+"-"+parent), s)
+	}
+	return template.HTML("subclass of " + s + " (" + html.EscapeString(n.Get("ParentFilename")) + ")")
+}
+
+func (f docForm) Children(n *transform.Node, name string) []*transform.Node {
+	var nodes []*transform.Node
+	for _, c := range n.Children {
+		if c.Name == name {
+			nodes = append(nodes, c)
+		}
+	}
+	return nodes
+}
+
+// With binds the form to a node, for sub-templates.
+func (f docForm) With(n *transform.Node) struct {
+	F docForm
+	N *transform.Node
+} {
+	return struct {
+		F docForm
+		N *transform.Node
+	}{F: f, N: n}
+}
+
+// RecordGroup returns the record group of the LOV.
+func (f docForm) RecordGroup(lov *transform.Node) *transform.Node {
+	return f.Module.Child("RecordGroup", lov.Get("RecordGroupName"))
+}
+
+var (
+	rPLSQLToken = regexp.MustCompile(`(?s)--[^\n]*|/\*.*?\*/|'(?:[^']|'')*'|[A-Za-z_][A-Za-z0-9_$#]*`)
+
+	plsqlKeywords = make(map[string]struct{})
+)
+
+func init() {
+	for _, k := range strings.Fields(`AND AS BEGIN BETWEEN BODY BY CASE CLOSE CURSOR DECLARE DEFAULT DELETE
+		DISTINCT ELSE ELSIF END EXCEPTION EXISTS EXIT FETCH FOR FROM FUNCTION GROUP HAVING IF IN INSERT INTO IS
+		LIKE LOOP NOT NULL OF ON OPEN OR ORDER OTHERS OUT PACKAGE PRAGMA PROCEDURE RAISE RECORD RETURN ROWTYPE
+		SELECT SET SQL THEN TO TYPE UPDATE VALUES WHEN WHERE WHILE`) {
+		plsqlKeywords[k] = struct{}{}
+	}
+}
+
+// PLSQL returns the syntax-highlighted PL/SQL text.
+func (f docForm) PLSQL(s string) template.HTML {
+	s = transform.Text(s)
+	var buf strings.Builder
+	var last int
+	for _, loc := range rPLSQLToken.FindAllStringIndex(s, -1) {
+		buf.WriteString(html.EscapeString(s[last:loc[0]]))
+		tok := s[loc[0]:loc[1]]
+		last = loc[1]
+		var class string
+		switch {
+		case strings.HasPrefix(tok, "--") || strings.HasPrefix(tok, "/*"):
+			class = "c"
+		case strings.HasPrefix(tok, "'"):
+			class = "s"
+		default:
+			if _, ok := plsqlKeywords[strings.ToUpper(tok)]; ok {
+				class = "k"
+			}
+		}
+		if class == "" {
+			buf.WriteString(html.EscapeString(tok))
+		} else {
+			fmt.Fprintf(&buf, `<span class="%s">%s</span>`, class, html.EscapeString(tok))
+		}
+	}
+	buf.WriteString(html.EscapeString(s[last:]))
+	return template.HTML(buf.String())
+}
+
+const docStyle = `<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+td, th { border: 1px solid #ccc; padding: 2px 6px; text-align: left; vertical-align: top; }
+pre { background: #f6f6f6; padding: 6px; overflow-x: auto; }
+.k { color: #00008b; font-weight: bold; } .s { color: #a31515; } .c { color: #008000; font-style: italic; }
+.props { font-size: smaller; color: #555; }
+</style>`
+
+var docFuncs = template.FuncMap{"Text": transform.Text, "Anchor": docAnchor}
+
+var docFormTmpl = template.Must(template.New("form").Funcs(docFuncs).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Name}}</title>` + docStyle + `</head>
+<body>
+<p><a href="index.html">Index</a></p>
+<h1>{{.Name}}</h1>
+<p>{{.File}}</p>
+{{define "props"}}<details class="props"><summary>Properties</summary><table>
+{{range .}}<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{end}}</table></details>{{end}}
+{{$f := .}}{{$m := .Module}}
+<h2>Module</h2>
+{{template "props" $f.Attrs $m}}
+
+{{with $f.Children $m "AttachedLibrary"}}<h2>Attached libraries</h2><ul>
+{{range .}}<li>{{.Get "Name"}} ({{.Get "LibraryLocation"}})</li>
+{{end}}</ul>{{end}}
+
+{{with $f.Children $m "ModuleParameter"}}<h2>Parameters</h2><table>
+<tr><th>Name</th><th>Type</th><th>Length</th><th>Initial value</th><th></th></tr>
+{{range .}}<tr id="{{Anchor .}}"><td>{{.Get "Name"}}</td><td>{{.Get "ParameterDataType"}}</td><td>{{.Get "MaximumLength"}}</td><td>{{.Get "ParameterInitializeValue"}}</td><td>{{$f.Parent .}}</td></tr>
+{{end}}</table>{{end}}
+
+{{with $f.Children $m "Block"}}<h2>Blocks</h2>
+{{range .}}{{$b := .}}<h3 id="{{Anchor $b}}">Block {{$b.Get "Name"}}</h3>
+<p>{{with $b.Get "QueryDataSourceName"}}Data source: <b>{{.}}</b>{{with $b.Get "QueryDataSourceType"}} ({{.}}){{end}}{{else}}Control block{{end}}
+{{with $b.Get "WhereClause"}}<br>WHERE {{Text .}}{{end}}{{with $b.Get "OrderByClause"}}<br>ORDER BY {{Text .}}{{end}}
+{{with $f.Parent $b}}<br>{{.}}{{end}}</p>
+{{with $f.Children $b "DataSourceColumn"}}<table><tr><th>Column</th><th>Type</th><th>Length</th></tr>
+{{range .}}<tr><td>{{.Get "DSCName"}}</td><td>{{.Get "DSCType"}}</td><td>{{.Get "DSCLength"}}</td></tr>
+{{end}}</table>{{end}}
+{{with $f.Children $b "Item"}}<table><tr><th>Item</th><th>Type</th><th>Prompt</th><th>Data type</th><th>Column</th><th>Canvas</th><th>LOV</th><th></th></tr>
+{{range .}}<tr id="{{Anchor .}}"><td>{{.Get "Name"}}</td><td>{{.Get "ItemType"}}</td><td>{{Text (.Get "Prompt")}}</td><td>{{.Get "DataType"}}{{with .Get "MaximumLength"}}({{.}}){{end}}</td><td>{{.Get "ColumnName"}}</td><td>{{.Get "CanvasName"}}</td><td>{{with .Get "LovName"}}<a href="#LOV-{{.}}">{{.}}</a>{{end}}</td><td>{{$f.Parent .}}</td></tr>
+{{end}}</table>{{end}}
+{{template "triggers" ($f.With $b)}}
+{{end}}{{end}}
+
+{{with $f.Children $m "LOV"}}<h2>LOVs</h2>
+{{range .}}<h3 id="{{Anchor .}}">LOV {{.Get "Name"}}</h3>
+<p>{{Text (.Get "Title")}} {{$f.Parent .}}</p>
+{{with $f.RecordGroup .}}<p>Record group <b>{{.Get "Name"}}</b></p>{{with .Get "RecordGroupQuery"}}<pre>{{$f.PLSQL .}}</pre>{{end}}{{end}}
+{{end}}{{end}}
+
+{{with $f.Children $m "Canvas"}}<h2>Canvases</h2><ul>
+{{range .}}<li id="{{Anchor .}}">{{.Get "Name"}} {{.Get "CanvasType"}} {{.Get "Width"}}×{{.Get "Height"}} {{$f.Parent .}}</li>
+{{end}}</ul>{{end}}
+
+{{with $f.Children $m "Window"}}<h2>Windows</h2><ul>
+{{range .}}<li id="{{Anchor .}}">{{.Get "Name"}} {{Text (.Get "Title")}} {{$f.Parent .}}</li>
+{{end}}</ul>{{end}}
+
+{{template "triggers" ($f.With $m)}}
+
+{{with $f.Children $m "ProgramUnit"}}<h2>Program units</h2>
+{{range .}}<h3 id="{{Anchor .}}">{{.Get "ProgramUnitType"}} {{.Get "Name"}}</h3>
+<pre>{{$f.PLSQL (.Get "ProgramUnitText")}}</pre>
+{{end}}{{end}}
+
+{{define "triggers"}}{{$f := .F}}{{with $f.Children .N "Trigger"}}<h4>Triggers</h4>
+{{range .}}<h5 id="{{Anchor .}}">{{.Get "Name"}} {{$f.Parent .}}</h5>
+{{with .Get "TriggerText"}}<pre>{{$f.PLSQL .}}</pre>{{end}}
+{{end}}{{end}}{{end}}
+</body></html>
+`))
+
+var docIndexTmpl = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Forms</title>` + docStyle + `</head>
+<body>
+<h1>Forms</h1>
+<p><input id="q" type="search" placeholder="Search objects..." autofocus size="40"></p>
+<ul>
+{{range .}}<li><a href="{{.Name}}.html">{{.Name}}</a> {{.File}}</li>
+{{end}}</ul>
+<table id="objects"><tr><th>Form</th><th>Type</th><th>Name</th></tr>
+{{range $f := .}}{{range .Objects}}<tr><td>{{$f.Name}}</td><td>{{.Type}}</td><td><a href="{{$f.Name}}.html#{{.Anchor}}">{{.Name}}</a></td></tr>
+{{end}}{{end}}</table>
+<script>
+document.getElementById("q").addEventListener("input", function(e) {
+  var q = e.target.value.toUpperCase();
+  var rows = document.getElementById("objects").rows;
+  for (var i = 1; i < rows.length; i++) {
+    rows[i].style.display = rows[i].textContent.toUpperCase().indexOf(q) >= 0 ? "" : "none";
+  }
+});
+</script>
+</body></html>
+`))
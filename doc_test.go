@@ -0,0 +1,98 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const docLibXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Module version="101020002" xmlns="http://xmlns.oracle.com/Forms">
+  <FormModule Name="G_LIB">
+    <Block Name="B">
+      <Item Name="X" ItemType="Text Item"/>
+      <Trigger Name="WHEN-NEW-BLOCK-INSTANCE" TriggerText="null;"/>
+    </Block>
+    <Window Name="W_MAIN"/>
+  </FormModule>
+</Module>`
+
+const docFormXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Module version="101020002" xmlns="http://xmlns.oracle.com/Forms">
+  <FormModule Name="EMP">
+    <AttachedLibrary Name="G_LIB" LibraryLocation="G_LIB.pll"/>
+    <Block Name="EMP" QueryDataSourceName="EMP">
+      <Item Name="X" ItemType="Text Item" ParentModule="G_LIB" ParentName="X" ParentSourceLevel1ObjectName="B" ParentFilename="G_LIB.fmb"/>
+      <Trigger Name="WHEN-NEW-BLOCK-INSTANCE" ParentModule="G_LIB" ParentName="WHEN-NEW-BLOCK-INSTANCE" ParentSourceLevel1ObjectName="B"/>
+    </Block>
+    <Trigger Name="WHEN-NEW-FORM-INSTANCE" TriggerText="begin&#10;  x := 'a &amp;amp;#10; b'; -- R&amp;D&#10;end;"/>
+    <Window Name="W_MAIN" ParentModule="G_LIB" ParentName="W_MAIN"/>
+  </FormModule>
+</Module>`
+
+func TestDoc(t *testing.T) {
+	dir := t.TempDir()
+	files := writeTestForms(t, dir, map[string]string{"g_lib": docLibXML, "emp": docFormXML})
+	dst := filepath.Join(dir, "doc")
+	if err := docFiles(context.Background(), nil, dst, files); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	read := func(name string) string {
+		b, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	lib, form := read("G_LIB.html"), read("EMP.html")
+
+	// each subclass link points to an existing anchor of the library
+	rLink := regexp.MustCompile(`<a href="G_LIB.html#([^"]+)">`)
+	links := rLink.FindAllStringSubmatch(form, -1)
+	if len(links) != 3 {
+		t.Errorf("got %d links to G_LIB, wanted 3", len(links))
+	}
+	for _, m := range links {
+		if !strings.Contains(lib, `id="`+m[1]+`"`) {
+			t.Errorf("no anchor %q in G_LIB.html", m[1])
+		}
+	}
+	for _, want := range []string{
+		`>G_LIB.B.X</a> (G_LIB.fmb)`,
+		`>G_LIB.B.WHEN-NEW-BLOCK-INSTANCE</a>`,
+		// the libraries (.pll) are not documented, so not linked
+		`<li>G_LIB (G_LIB.pll)</li>`,
+		// the character references are resolved once
+		`<span class="s">&#39;a &amp;#10; b&#39;</span>`,
+		`<span class="c">-- R&amp;D</span>`,
+	} {
+		if !strings.Contains(form, want) {
+			t.Errorf("%q is missing from\n%s", want, form)
+		}
+	}
+
+	index := read("index.html")
+	for _, want := range []string{`<a href="EMP.html">EMP</a>`, `<a href="EMP.html#Item-EMP.X">EMP.X</a>`} {
+		if !strings.Contains(index, want) {
+			t.Errorf("%q is missing from\n%s", want, index)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+// ObsoleteBuiltins are the Forms 6i built-ins removed or unsupported on the web (9i+).
+var ObsoleteBuiltins = []string{
+	"BREAK", "CALL", "CALL_QUERY", "DEFAULT_COMMIT", "ITEM_ENABLED",
+	"MENU_CLEAR_FIELD", "MENU_FAILURE", "MENU_HELP", "MENU_MESSAGE", "MENU_NEXT_FIELD",
+	"MENU_PARAMETER", "MENU_PREVIOUS_FIELD", "MENU_REDISPLAY", "MENU_SHOW_KEYS", "MENU_SUCCESS",
+	"NEW_APPLICATION", "NEW_USER", "OS_COMMAND", "OS_COMMAND1", "RUN_PRODUCT", "SET_INPUT_FOCUS",
+}
+
+var rObsoleteBuiltins = regexp.MustCompile(`(?i)(?:^|[^\w.$#])(` + strings.Join(ObsoleteBuiltins, "|") + `)\s*[(;]`)
+
+// estimateWeights are the effort (hours) per occurrence of each metric.
+type estimateWeights struct {
+	Form            float64 `json:"form"`
+	StackedCanvases float64 `json:"stackedCanvases"`
+	BadItemTypes    float64 `json:"badItemTypes"`
+	ObsoleteCalls   float64 `json:"obsoleteBuiltins"`
+	PLSQLLines      float64 `json:"plsqlLines"`
+	VAOverrides     float64 `json:"vaOverrides"`
+	Injected        float64 `json:"injected"`
+}
+
+var defaultEstimateWeights = estimateWeights{
+	Form:            2,
+	StackedCanvases: 4,
+	BadItemTypes:    0.5,
+	ObsoleteCalls:   1,
+	PLSQLLines:      0.01,
+	VAOverrides:     0.02,
+	Injected:        0.1,
+}
+
+// readEstimateWeights reads the JSON weights, over defaultEstimateWeights.
+func readEstimateWeights(fn string) (estimateWeights, error) {
+	weights := defaultEstimateWeights
+	if fn == "" {
+		return weights, nil
+	}
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return weights, err
+	}
+	if err = json.Unmarshal(b, &weights); err != nil {
+		return weights, fmt.Errorf("parse %q: %w", fn, err)
+	}
+	return weights, nil
+}
+
+type estimateForm struct {
+	File, Name string
+	transform.Stats
+	ObsoleteCalls, PLSQLLines int
+	Score                     float64
+}
+
+func (w estimateWeights) score(f estimateForm) float64 {
+	return w.Form +
+		w.StackedCanvases*float64(f.StackedCanvases) +
+		w.BadItemTypes*float64(f.BadItemTypes) +
+		w.ObsoleteCalls*float64(f.ObsoleteCalls) +
+		w.PLSQLLines*float64(f.PLSQLLines) +
+		w.VAOverrides*float64(f.VAOverrides) +
+		w.Injected*float64(f.Injected)
+}
+
+// newEstimateForm collects the metrics of the module, running the transformation on it.
+func newEstimateForm(fn string, root *transform.Node) (estimateForm, error) {
+	form := estimateForm{File: fn, Name: root.Module().Get("Name")}
+	for _, k := range []string{"Trigger", "ProgramUnit"} {
+		for _, n := range root.Module().Find(k) {
+			code := transform.Text(n.Get(k + "Text"))
+			if strings.TrimSpace(code) == "" {
+				continue
+			}
+			form.PLSQLLines += strings.Count(strings.TrimSpace(code), "\n") + 1
+			form.ObsoleteCalls += len(rObsoleteBuiltins.FindAllStringIndex(blankPLSQL(code), -1))
+		}
+	}
+	var P transform.FormsXMLProcessor
+	if _, err := P.ProcessTree(root); err != nil {
+		return form, fmt.Errorf("transform %q: %w", fn, err)
+	}
+	form.Stats = P.Stats
+	return form, nil
+}
+
+var estimateColumns = []string{"FILE", "FORM", "STACKED_CANVASES", "BAD_ITEM_TYPES", "OBSOLETE_BUILTINS", "PLSQL_LINES", "VA_OVERRIDES", "INJECTED", "SCORE"}
+
+// estimateFiles writes the weighted effort score of each form, and the total, as CSV or HTML.
+func estimateFiles(ctx context.Context, w io.Writer, converter Converter, weights estimateWeights, format string, files []string) error {
+	var forms []estimateForm
+	total := estimateForm{File: "TOTAL"}
+	for _, fn := range files {
+		root, err := readForm(ctx, converter, fn)
+		if err != nil {
+			return err
+		}
+		form, err := newEstimateForm(fn, root)
+		if err != nil {
+			return err
+		}
+		form.Score = weights.score(form)
+		forms = append(forms, form)
+		total.StackedCanvases += form.StackedCanvases
+		total.BadItemTypes += form.BadItemTypes
+		total.ObsoleteCalls += form.ObsoleteCalls
+		total.PLSQLLines += form.PLSQLLines
+		total.VAOverrides += form.VAOverrides
+		total.Injected += form.Injected
+		total.Score += form.Score
+	}
+	if format == "html" {
+		return estimateTmpl.Execute(w, struct {
+			Weights estimateWeights
+			Columns []string
+			Forms   []estimateForm
+			Total   estimateForm
+		}{weights, estimateColumns, forms, total})
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(estimateColumns)
+	for _, f := range append(forms, total) {
+		cw.Write([]string{f.File, f.Name,
+			strconv.Itoa(f.StackedCanvases), strconv.Itoa(f.BadItemTypes), strconv.Itoa(f.ObsoleteCalls),
+			strconv.Itoa(f.PLSQLLines), strconv.Itoa(f.VAOverrides), strconv.Itoa(f.Injected),
+			strconv.FormatFloat(f.Score, 'f', 1, 64)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+var estimateTmpl = template.Must(template.New("estimate").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>6to11 effort estimate</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; }
+td.n { text-align: right; }
+tfoot { font-weight: bold; }
+</style></head>
+<body>
+<h1>6to11 effort estimate</h1>
+<p>Weights (hours): form {{.Weights.Form}}, stacked canvas {{.Weights.StackedCanvases}},
+bad item type {{.Weights.BadItemTypes}}, obsolete built-in {{.Weights.ObsoleteCalls}},
+PL/SQL line {{.Weights.PLSQLLines}}, VA override {{.Weights.VAOverrides}}, injected object {{.Weights.Injected}}.</p>
+<table>
+<thead><tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Forms}}{{template "row" .}}{{end}}</tbody>
+<tfoot>{{template "row" .Total}}</tfoot>
+</table>
+</body></html>
+{{define "row"}}<tr><td>{{.File}}</td><td>{{.Name}}</td><td class="n">{{.StackedCanvases}}</td><td class="n">{{.BadItemTypes}}</td><td class="n">{{.ObsoleteCalls}}</td><td class="n">{{.PLSQLLines}}</td><td class="n">{{.VAOverrides}}</td><td class="n">{{.Injected}}</td><td class="n">{{printf "%.1f" .Score}}</td></tr>
+{{end}}`))
@@ -0,0 +1,121 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+	"github.com/google/go-cmp/cmp"
+)
+
+const estimateXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Module version="101020002" xmlns="http://xmlns.oracle.com/Forms">
+  <FormModule Name="EMP">
+    <Block Name="EMP">
+      <Item Name="A" ItemType="Text Item" CanvasName="C_STACK" FontName="Arial"/>
+      <Trigger Name="WHEN-BUTTON-PRESSED" TriggerText="%s"/>
+    </Block>
+    <Canvas Name="C_STACK" CanvasType="Stacked"/>
+  </FormModule>
+</Module>`
+
+func TestEstimateObsoleteCalls(t *testing.T) {
+	for _, tc := range []struct {
+		Code string
+		Want int
+	}{
+		{Code: "break;", Want: 1},
+		{Code: "Call('EMP');&amp;#10;run_product(REPORTS, 'r', SYNCHRONOUS, RUNTIME, FILESYSTEM, NULL, NULL);", Want: 2},
+		{Code: "os_command1 ('ls');", Want: 1},
+		// not a call of the built-in
+		{Code: "pkg.call(1); my_break; v_call := 1; call_form('X');"},
+		{Code: "-- break;&amp;#10;/* call('X'); */ null;"},
+		{Code: "message('Press BREAK; or CALL(x)');"},
+		{Code: "message(q'[Don't BREAK; or CALL(x)]');"},
+	} {
+		root, err := transform.ParseTree(strings.NewReader(strings.Replace(estimateXML, "%s", tc.Code, 1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		form, err := newEstimateForm("emp.xml", root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if form.ObsoleteCalls != tc.Want {
+			t.Errorf("%q: got %d, wanted %d", tc.Code, form.ObsoleteCalls, tc.Want)
+		}
+	}
+}
+
+func TestEstimateFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := writeTestForms(t, dir, map[string]string{
+		"emp": strings.Replace(estimateXML, "%s", "begin&amp;#10;  break;&amp;#10;end;", 1)})
+	weightsFn := filepath.Join(dir, "weights.json")
+	if err := os.WriteFile(weightsFn, []byte(`{"form": 1, "injected": 0}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	weights, err := readEstimateWeights(weightsFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if weights.Form != 1 || weights.StackedCanvases != defaultEstimateWeights.StackedCanvases {
+		t.Errorf("got %+v", weights)
+	}
+	ctx := context.Background()
+	for _, tc := range []struct {
+		Weights estimateWeights
+		Score   string
+	}{
+		// 1 form + 1 stacked canvas + 1 obsolete built-in + 3 PL/SQL lines
+		{Weights: weights, Score: "6.0"},
+		// and the injected objects
+		{Weights: defaultEstimateWeights, Score: "10.0"},
+	} {
+		var buf bytes.Buffer
+		if err = estimateFiles(ctx, &buf, nil, tc.Weights, "csv", files); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		rows, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := [][]string{
+			estimateColumns,
+			{files[0], "EMP", "1", "0", "1", "3", "0", "30", tc.Score},
+			{"TOTAL", "", "1", "0", "1", "3", "0", "30", tc.Score},
+		}
+		if d := cmp.Diff(want, rows); d != "" {
+			t.Error(d)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = estimateFiles(ctx, &buf, nil, weights, "html", files); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if s := buf.String(); !strings.Contains(s, "injected object 0.") ||
+		!strings.Contains(s, `<td class="n">30</td><td class="n">6.0</td></tr>`) {
+		t.Errorf("got %s", s)
+	}
+}
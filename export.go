@@ -0,0 +1,362 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+// DefaultSchemaTypes maps the Forms DataType of items to JSON Schema types.
+var DefaultSchemaTypes = map[string]map[string]any{
+	"Char":     {"type": "string"},
+	"Alpha":    {"type": "string"},
+	"Long":     {"type": "string"},
+	"Number":   {"type": "number"},
+	"Money":    {"type": "number"},
+	"Integer":  {"type": "integer"},
+	"Date":     {"type": "string", "format": "date"},
+	"Datetime": {"type": "string", "format": "date-time"},
+	"Time":     {"type": "string", "format": "time"},
+}
+
+// ExportForm is the web-oriented model of a form.
+type ExportForm struct {
+	// Name of the FormModule.
+	Name string `json:"name"`
+	// Title of the form (of the console window).
+	Title    string         `json:"title,omitempty"`
+	Blocks   []ExportBlock  `json:"blocks"`
+	Canvases []ExportCanvas `json:"canvases,omitempty"`
+	Windows  []ExportWindow `json:"windows,omitempty"`
+}
+
+// ExportBlock is a data block.
+type ExportBlock struct {
+	Name string `json:"name"`
+	// DataSource is the base table (QueryDataSourceName), empty for control blocks.
+	DataSource string `json:"dataSource,omitempty"`
+	Where      string `json:"where,omitempty"`
+	OrderBy    string `json:"orderBy,omitempty"`
+	// Records is the number of records displayed (>1 for multi-row blocks).
+	Records int          `json:"records,omitempty"`
+	Items   []ExportItem `json:"items"`
+}
+
+// ExportItem is an item of a block.
+type ExportItem struct {
+	Name     string `json:"name"`
+	ItemType string `json:"itemType"`
+	// DataType is the Forms data type (Char, Number, Date...).
+	DataType      string `json:"dataType,omitempty"`
+	MaximumLength int    `json:"maximumLength,omitempty"`
+	Required      bool   `json:"required,omitempty"`
+	FormatMask    string `json:"formatMask,omitempty"`
+	Column        string `json:"column,omitempty"`
+	Prompt        string `json:"prompt,omitempty"`
+	Hint          string `json:"hint,omitempty"`
+	Default       string `json:"default,omitempty"`
+	// LOV binding of the item.
+	LOV *ExportLOV `json:"lov,omitempty"`
+	// Elements of list items, radio groups and check boxes (checked, unchecked).
+	Elements []ExportElement `json:"elements,omitempty"`
+	Layout   *ExportLayout   `json:"layout,omitempty"`
+}
+
+// ExportLOV is a list of values with its record group query and column mapping.
+type ExportLOV struct {
+	Name    string            `json:"name"`
+	Title   string            `json:"title,omitempty"`
+	Query   string            `json:"query,omitempty"`
+	Columns []ExportLOVColumn `json:"columns,omitempty"`
+}
+
+// ExportLOVColumn maps a LOV column to the item it returns into.
+type ExportLOVColumn struct {
+	Name       string `json:"name"`
+	Title      string `json:"title,omitempty"`
+	ReturnItem string `json:"returnItem,omitempty"`
+}
+
+// ExportElement is a label-value pair of a list item, radio group or check box.
+type ExportElement struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// ExportLayout is the position of the item, in pixels.
+type ExportLayout struct {
+	Canvas  string `json:"canvas,omitempty"`
+	TabPage string `json:"tabPage,omitempty"`
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+}
+
+// ExportCanvas is a canvas, with its size in pixels.
+type ExportCanvas struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type,omitempty"`
+	Window   string   `json:"window,omitempty"`
+	Width    int      `json:"width"`
+	Height   int      `json:"height"`
+	TabPages []string `json:"tabPages,omitempty"`
+}
+
+// ExportWindow is a window.
+type ExportWindow struct {
+	Name   string `json:"name"`
+	Title  string `json:"title,omitempty"`
+	Canvas string `json:"primaryCanvas,omitempty"`
+}
+
+// exportFiles writes the model (<form>.json) and the JSON Schema of the data (<form>.schema.json) of each form into dstDir.
+func exportFiles(ctx context.Context, converter Converter, dstDir string, types map[string]map[string]any, files []string) error {
+	if err := os.MkdirAll(dstDir, 0755); err != nil {
+		return err
+	}
+	for _, fn := range files {
+		root, err := readForm(ctx, converter, fn)
+		if err != nil {
+			return err
+		}
+		form := newExportForm(root.Module())
+		if form.Name == "" {
+			form.Name = strings.TrimSuffix(filepath.Base(fn), filepath.Ext(fn))
+		}
+		base := filepath.Join(dstDir, form.Name)
+		log.Printf("Export %q to %q.", fn, base+".json")
+		if err = writeJSON(base+".json", form); err != nil {
+			return err
+		}
+		if err = writeJSON(base+".schema.json", form.Schema(types)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(fn string, v any) error {
+	return writeFile(fn, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	})
+}
+
+// readSchemaTypes reads the JSON type mapping, merged over DefaultSchemaTypes.
+func readSchemaTypes(fn string) (map[string]map[string]any, error) {
+	types := make(map[string]map[string]any, len(DefaultSchemaTypes))
+	for k, v := range DefaultSchemaTypes {
+		types[k] = v
+	}
+	if fn == "" {
+		return types, nil
+	}
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]map[string]any
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("parse %q: %w", fn, err)
+	}
+	for k, v := range m {
+		types[k] = v
+	}
+	return types, nil
+}
+
+func atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
+
+func newExportForm(module *transform.Node) ExportForm {
+	sx, sy := transform.PixelScale(module)
+	px := func(n *transform.Node, k string, s float64) int {
+		f, _ := strconv.ParseFloat(n.Get(k), 64)
+		return int(f * s)
+	}
+	form := ExportForm{Name: module.Get("Name"), Title: transform.Text(module.Get("Title"))}
+	for _, c := range module.Children {
+		switch c.Name {
+		case "Canvas":
+			cv := ExportCanvas{Name: c.Get("Name"), Type: c.Get("CanvasType"), Window: c.Get("WindowName"),
+				Width: px(c, "Width", sx), Height: px(c, "Height", sy)}
+			for _, tp := range c.Find("TabPage") {
+				cv.TabPages = append(cv.TabPages, tp.Get("Name"))
+			}
+			form.Canvases = append(form.Canvases, cv)
+		case "Window":
+			form.Windows = append(form.Windows, ExportWindow{Name: c.Get("Name"),
+				Title: transform.Text(c.Get("Title")), Canvas: c.Get("PrimaryCanvas")})
+			if form.Title == "" && c.Get("Name") == module.Get("ConsoleWindow") {
+				form.Title = transform.Text(c.Get("Title"))
+			}
+		case "Block":
+			b := ExportBlock{Name: c.Get("Name"), DataSource: c.Get("QueryDataSourceName"),
+				Where: transform.Text(c.Get("WhereClause")), OrderBy: transform.Text(c.Get("OrderByClause")),
+				Records: atoi(c.Get("RecordsDisplayCount"))}
+			for _, n := range c.Children {
+				if n.Name != "Item" {
+					continue
+				}
+				it := ExportItem{Name: n.Get("Name"), ItemType: n.Get("ItemType"),
+					DataType: n.Get("DataType"), MaximumLength: atoi(n.Get("MaximumLength")),
+					Required: n.Get("Required") == "true", FormatMask: n.Get("FormatMask"),
+					Column: n.Get("ColumnName"), Prompt: transform.Text(n.Get("Prompt")),
+					Hint: transform.Text(n.Get("Hint")), Default: n.Get("InitializeValue"),
+				}
+				if it.Column == "" && n.Get("DatabaseItem") != "false" && b.DataSource != "" {
+					it.Column = it.Name
+				}
+				if lovName := n.Get("LovName"); lovName != "" {
+					it.LOV = exportLOV(module, lovName)
+				}
+				for _, e := range n.Children {
+					switch e.Name {
+					case "ListItemElement":
+						it.Elements = append(it.Elements, ExportElement{Label: transform.Text(e.Get("Name")), Value: e.Get("Value")})
+					case "RadioButton":
+						it.Elements = append(it.Elements, ExportElement{Label: transform.Text(e.Get("Label")), Value: e.Get("RadioButtonValue")})
+					}
+				}
+				if it.ItemType == "Check Box" {
+					it.Elements = append(it.Elements,
+						ExportElement{Label: "checked", Value: n.Get("CheckedValue")},
+						ExportElement{Label: "unchecked", Value: n.Get("UncheckedValue")})
+				}
+				if cv := n.Get("CanvasName"); cv != "" {
+					it.Layout = &ExportLayout{Canvas: cv, TabPage: n.Get("TabPageName"),
+						X: px(n, "XPosition", sx), Y: px(n, "YPosition", sy),
+						Width: px(n, "Width", sx), Height: px(n, "Height", sy)}
+				}
+				b.Items = append(b.Items, it)
+			}
+			form.Blocks = append(form.Blocks, b)
+		}
+	}
+	return form
+}
+
+func exportLOV(module *transform.Node, name string) *ExportLOV {
+	lov := ExportLOV{Name: name}
+	n := module.Child("LOV", name)
+	if n == nil {
+		return &lov
+	}
+	lov.Title = transform.Text(n.Get("Title"))
+	if rg := module.Child("RecordGroup", n.Get("RecordGroupName")); rg != nil {
+		lov.Query = transform.Text(rg.Get("RecordGroupQuery"))
+	}
+	for _, m := range n.Find("LOVColumnMapping") {
+		lov.Columns = append(lov.Columns, ExportLOVColumn{Name: m.Get("Name"),
+			Title: transform.Text(m.Get("Title")), ReturnItem: m.Get("ReturnItem")})
+	}
+	return &lov
+}
+
+// jsonValue converts the literal Forms value to the JSON Schema type.
+// It reports false for the values not of the type, and the non-literal initial values:
+// the system variables ($$DATE$$), and the references (:BLOCK.ITEM, :GLOBAL.X, :SEQUENCE.S.NEXTVAL).
+func jsonValue(typ any, s string) (any, bool) {
+	if strings.HasPrefix(s, "$$") || strings.HasPrefix(s, ":") {
+		return nil, false
+	}
+	switch typ {
+	case "number":
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil
+	case "integer":
+		i, err := strconv.ParseInt(s, 10, 64)
+		return i, err == nil
+	case "boolean":
+		b, err := strconv.ParseBool(s)
+		return b, err == nil
+	}
+	return s, true
+}
+
+// Schema returns the JSON Schema of the form's data: an object with an array of records for each block.
+func (form ExportForm) Schema(types map[string]map[string]any) map[string]any {
+	blocks := make(map[string]any, len(form.Blocks))
+	for _, b := range form.Blocks {
+		props := make(map[string]any, len(b.Items))
+		var required []string
+		for _, it := range b.Items {
+			if it.ItemType == "Push Button" || it.ItemType == "Button" {
+				continue
+			}
+			prop := make(map[string]any)
+			for k, v := range types[it.DataType] {
+				prop[k] = v
+			}
+			if len(prop) == 0 {
+				prop["type"] = "string"
+			}
+			if it.MaximumLength > 0 && prop["type"] == "string" && prop["format"] == nil {
+				prop["maxLength"] = it.MaximumLength
+			}
+			if desc := firstNonEmpty(it.Prompt, it.Hint); desc != "" {
+				prop["description"] = desc
+			}
+			if v, ok := jsonValue(prop["type"], it.Default); ok && it.Default != "" {
+				prop["default"] = v
+			}
+			if len(it.Elements) != 0 {
+				enum := make([]any, 0, len(it.Elements))
+				for _, e := range it.Elements {
+					if v, ok := jsonValue(prop["type"], e.Value); ok {
+						enum = append(enum, v)
+					}
+				}
+				if len(enum) != 0 {
+					prop["enum"] = enum
+				}
+			}
+			props[it.Name] = prop
+			if it.Required {
+				required = append(required, it.Name)
+			}
+		}
+		record := map[string]any{"type": "object", "properties": props}
+		if len(required) != 0 {
+			record["required"] = required
+		}
+		if b.DataSource != "" {
+			record["description"] = b.DataSource
+		}
+		blocks[b.Name] = map[string]any{"type": "array", "items": record}
+	}
+	return map[string]any{
+		"$schema":    "https://json-schema.org/draft/2020-12/schema",
+		"$id":        form.Name + ".schema.json",
+		"title":      firstNonEmpty(form.Title, form.Name),
+		"type":       "object",
+		"properties": blocks,
+	}
+}
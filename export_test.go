@@ -0,0 +1,105 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const exportXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Module version="101020002" xmlns="http://xmlns.oracle.com/Forms">
+  <FormModule Name="EMP" ConsoleWindow="W_MAIN">
+    <Block Name="EMP" QueryDataSourceName="EMP" RecordsDisplayCount="10">
+      <Item Name="EMPNO" ItemType="Text Item" DataType="Integer" Required="true" InitializeValue="1" Prompt="Number"/>
+      <Item Name="ENAME" ItemType="Text Item" DataType="Char" MaximumLength="10" InitializeValue=":GLOBAL.ENAME"
+        CanvasName="C_MAIN" XPosition="1" YPosition="2" Width="10" Height="1"/>
+      <Item Name="HIREDATE" ItemType="Text Item" DataType="Date" InitializeValue="$$DATE$$"/>
+      <Item Name="STATUS" ItemType="Radio Group" DataType="Number" InitializeValue="1">
+        <RadioButton Name="ACTIVE" Label="Active" RadioButtonValue="1"/>
+        <RadioButton Name="LEFT" Label="Left" RadioButtonValue="2.5"/>
+      </Item>
+      <Item Name="JOB" ItemType="List Item" DataType="Char" InitializeValue="CLERK">
+        <ListItemElement Name="Clerk" Value="CLERK"/>
+        <ListItemElement Name="Manager" Value="MANAGER"/>
+      </Item>
+      <Item Name="OK" ItemType="Push Button"/>
+    </Block>
+    <Canvas Name="C_MAIN" CanvasType="Content" WindowName="W_MAIN" Width="80" Height="24"/>
+    <Window Name="W_MAIN" Title="Employees" PrimaryCanvas="C_MAIN"/>
+  </FormModule>
+</Module>`
+
+func TestExport(t *testing.T) {
+	dir := t.TempDir()
+	files := writeTestForms(t, dir, map[string]string{"emp": exportXML})
+	dst := filepath.Join(dir, "out")
+	if err := exportFiles(context.Background(), nil, dst, DefaultSchemaTypes, files); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	var form ExportForm
+	readJSON(t, filepath.Join(dst, "EMP.json"), &form)
+	if form.Title != "Employees" || len(form.Blocks) != 1 || len(form.Blocks[0].Items) != 6 || form.Blocks[0].Records != 10 {
+		t.Errorf("got %+v", form)
+	}
+	if it := form.Blocks[0].Items[1]; it.Column != "ENAME" || it.Layout == nil || it.Layout.Canvas != "C_MAIN" {
+		t.Errorf("got %+v", it)
+	}
+
+	var schema struct {
+		Properties map[string]struct {
+			Items struct {
+				Properties map[string]map[string]any
+				Required   []string
+			}
+		}
+	}
+	readJSON(t, filepath.Join(dst, "EMP.schema.json"), &schema)
+	rec := schema.Properties["EMP"].Items
+	if d := cmp.Diff([]string{"EMPNO"}, rec.Required); d != "" {
+		t.Error(d)
+	}
+	for name, want := range map[string]map[string]any{
+		"EMPNO": {"type": "integer", "default": 1.0, "description": "Number"},
+		// a reference and a system variable are not defaults
+		"ENAME":    {"type": "string", "maxLength": 10.0},
+		"HIREDATE": {"type": "string", "format": "date"},
+		"STATUS":   {"type": "number", "default": 1.0, "enum": []any{1.0, 2.5}},
+		"JOB":      {"type": "string", "default": "CLERK", "enum": []any{"CLERK", "MANAGER"}},
+		"OK":       nil,
+	} {
+		if d := cmp.Diff(want, rec.Properties[name]); d != "" {
+			t.Errorf("%s: %s", name, d)
+		}
+	}
+}
+
+func readJSON(t *testing.T, fn string, v any) {
+	t.Helper()
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = json.Unmarshal(b, v); err != nil {
+		t.Fatalf("%s: %+v", fn, err)
+	}
+}
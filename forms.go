@@ -0,0 +1,102 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+// isFmb reports whether the file is a binary form (and not XML).
+func isFmb(fn string) bool {
+	return strings.HasSuffix(strings.ToLower(fn), ".fmb")
+}
+
+// readForm reads the Forms XML tree of the file, converting .fmb files to XML with the converter.
+func readForm(ctx context.Context, converter Converter, fn string) (*transform.Node, error) {
+	fh, err := os.Open(fn)
+	if err != nil {
+		return nil, fmt.Errorf("open %q: %w", fn, err)
+	}
+	defer fh.Close()
+	if !isFmb(fn) {
+		root, err := transform.ParseTree(fh)
+		if err != nil {
+			return nil, fmt.Errorf("parse %q: %w", fn, err)
+		}
+		return root, nil
+	}
+	var buf bytes.Buffer
+	if err = converter.Convert(ctx, &buf, fh, "application/x-oracle-forms"); err != nil {
+		return nil, fmt.Errorf("convert %q: %w", fn, err)
+	}
+	root, err := transform.ParseTree(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("parse %q: %w", fn, err)
+	}
+	return root, nil
+}
+
+// writeForm writes the Forms XML tree into the file, converting it to binary for .fmb files.
+func writeForm(ctx context.Context, converter Converter, fn string, root *transform.Node) error {
+	var buf bytes.Buffer
+	if _, err := root.WriteTo(&buf); err != nil {
+		return fmt.Errorf("encode %q: %w", fn, err)
+	}
+	err := writeFileAtomic(fn, func(w io.Writer) error {
+		if !isFmb(fn) {
+			_, err := buf.WriteTo(w)
+			return err
+		}
+		return converter.Convert(ctx, w, &buf, "application/xml")
+	})
+	if err != nil {
+		return fmt.Errorf("write %q: %w", fn, err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes the file with write into a temporary file in the same directory,
+// which replaces fn only if write succeeds, so a failed conversion leaves the original intact.
+func writeFileAtomic(fn string, write func(io.Writer) error) error {
+	mode := os.FileMode(0o644)
+	if fi, err := os.Stat(fn); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	fh, err := os.CreateTemp(filepath.Dir(fn), "."+filepath.Base(fn)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(fh.Name())
+	defer fh.Close()
+	if err = write(fh); err != nil {
+		return err
+	}
+	if err = fh.Chmod(mode); err != nil {
+		return err
+	}
+	if err = fh.Close(); err != nil {
+		return err
+	}
+	return os.Rename(fh.Name(), fn)
+}
@@ -0,0 +1,471 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+// i18nAttrs are the translatable attributes by element.
+var i18nAttrs = map[string][]string{
+	"Item":             {"Prompt", "Hint", "Label"},
+	"RadioButton":      {"Prompt", "Label"},
+	"ListItemElement":  {"Name"},
+	"Window":           {"Title"},
+	"Alert":            {"Title", "AlertMessage", "Button1Label", "Button2Label", "Button3Label"},
+	"TabPage":          {"Label"},
+	"LOV":              {"Title"},
+	"LOVColumnMapping": {"Title"},
+	"Graphics":         {"FrameTitle"},
+	"TextSegment":      {"Text"},
+	"Trigger":          {"TriggerText"},
+	"ProgramUnit":      {"ProgramUnitText"},
+}
+
+// rMessage matches the string literal argument of MESSAGE('…').
+var rMessage = regexp.MustCompile(`(?i)\bMESSAGE\s*\(\s*'((?:[^']|'')*)'`)
+
+// i18nUnit is a translatable string: the whole value of Attr,
+// or the Msg-th MESSAGE literal in it, if Msg >= 0.
+type i18nUnit struct {
+	Key, Source string
+	Node        *transform.Node
+	Attr        string
+	Msg         int
+}
+
+// i18nPath is the path of the node, stable under translation:
+// element without Name (and ListItemElement, whose Name is its label) is indexed.
+func i18nPath(n *transform.Node) string {
+	var parts []string
+	for c := n; c != nil && c.Name != "Module"; c = c.Parent {
+		s := c.Name
+		if nm := c.Get("Name"); nm != "" && c.Name != "ListItemElement" {
+			s += "[" + nm + "]"
+		} else if c.Parent != nil {
+			var i int
+			for _, sib := range c.Parent.Children {
+				if sib == c {
+					break
+				}
+				if sib.Name == c.Name {
+					i++
+				}
+			}
+			s += "[#" + strconv.Itoa(i) + "]"
+		}
+		parts = append(parts, s)
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, "/")
+}
+
+// i18nUnits collects the translatable strings of the module.
+func i18nUnits(module *transform.Node) []i18nUnit {
+	var units []i18nUnit
+	module.Walk(func(n *transform.Node) error {
+		for _, k := range i18nAttrs[n.Name] {
+			v := n.Get(k)
+			if strings.TrimSpace(v) == "" {
+				continue
+			}
+			key := i18nPath(n) + "#" + k
+			if !strings.HasSuffix(k, "Text") || n.Name == "TextSegment" {
+				units = append(units, i18nUnit{Key: key, Source: transform.Text(v), Node: n, Attr: k, Msg: -1})
+				continue
+			}
+			for i, m := range rMessage.FindAllStringSubmatch(v, -1) {
+				if s := strings.ReplaceAll(m[1], "''", "'"); strings.TrimSpace(s) != "" {
+					units = append(units, i18nUnit{Key: key + ":MESSAGE:" + strconv.Itoa(i),
+						Source: transform.Text(s), Node: n, Attr: k, Msg: i})
+				}
+			}
+		}
+		return nil
+	})
+	return units
+}
+
+// i18nEntry is a translation.
+type i18nEntry struct {
+	Key, Source, Target string
+	// Fuzzy marks translations needing review (source changed).
+	Fuzzy bool
+}
+
+// i18nCatalog is a translation file.
+type i18nCatalog struct {
+	SourceLang, TargetLang string
+	Entries                []i18nEntry
+}
+
+func (c i18nCatalog) byKey() map[string]i18nEntry {
+	m := make(map[string]i18nEntry, len(c.Entries))
+	for _, e := range c.Entries {
+		m[e.Key] = e
+	}
+	return m
+}
+
+func isXLIFF(fn string) bool {
+	switch strings.ToLower(filepath.Ext(fn)) {
+	case ".xlf", ".xliff":
+		return true
+	}
+	return false
+}
+
+// readCatalog reads the .po or .xlf/.xliff translation file.
+func readCatalog(fn string) (i18nCatalog, error) {
+	fh, err := os.Open(fn)
+	if err != nil {
+		return i18nCatalog{}, err
+	}
+	defer fh.Close()
+	var cat i18nCatalog
+	if isXLIFF(fn) {
+		cat, err = readXLIFF(fh)
+	} else {
+		cat, err = readPO(fh)
+	}
+	if err != nil {
+		return cat, fmt.Errorf("parse %q: %w", fn, err)
+	}
+	return cat, nil
+}
+
+// writeCatalog writes the catalog into fn ("" or "-" for stdout), as XLIFF if format is "xliff".
+func writeCatalog(fn, format string, cat i18nCatalog) error {
+	write := writePO
+	if format == "xliff" {
+		write = writeXLIFF
+	}
+	if fn == "" || fn == "-" {
+		return write(os.Stdout, cat)
+	}
+	return writeFile(fn, func(w io.Writer) error { return write(w, cat) })
+}
+
+func poQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`).Replace(s) + `"`
+}
+
+func poUnquote(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("bad string %s", s)
+	}
+	return strconv.Unquote(s)
+}
+
+func writePO(w io.Writer, cat i18nCatalog) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "msgid \"\"\nmsgstr \"\"\n%s\n%s\n%s\n",
+		poQuote("Content-Type: text/plain; charset=UTF-8\n"),
+		poQuote("Language: "+cat.TargetLang+"\n"),
+		poQuote("X-Source-Language: "+cat.SourceLang+"\n"))
+	for _, e := range cat.Entries {
+		fmt.Fprintf(bw, "\n#: %s\n", e.Key)
+		if e.Fuzzy {
+			bw.WriteString("#, fuzzy\n")
+		}
+		fmt.Fprintf(bw, "msgctxt %s\nmsgid %s\nmsgstr %s\n", poQuote(e.Key), poQuote(e.Source), poQuote(e.Target))
+	}
+	return bw.Flush()
+}
+
+func readPO(r io.Reader) (i18nCatalog, error) {
+	var cat i18nCatalog
+	var e i18nEntry
+	var field *string
+	var fuzzy, started bool
+	flush := func() {
+		if !started {
+			return
+		}
+		if e.Key == "" && e.Source == "" { // header
+			for _, line := range strings.Split(e.Target, "\n") {
+				if k, v, ok := strings.Cut(line, ":"); ok {
+					switch strings.TrimSpace(k) {
+					case "Language":
+						cat.TargetLang = strings.TrimSpace(v)
+					case "X-Source-Language":
+						cat.SourceLang = strings.TrimSpace(v)
+					}
+				}
+			}
+		} else {
+			cat.Entries = append(cat.Entries, e)
+		}
+		e, started = i18nEntry{}, false
+	}
+	start := func() {
+		flush()
+		e.Fuzzy, fuzzy, started = fuzzy, false, true
+	}
+	var lineNo int
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), 16<<20)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		var rest string
+		switch {
+		case strings.HasPrefix(line, "#,"):
+			fuzzy = strings.Contains(line, "fuzzy")
+			continue
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "msgctxt "):
+			start()
+			field, rest = &e.Key, line[len("msgctxt "):]
+		case strings.HasPrefix(line, "msgid "):
+			if !started || field == &e.Target {
+				start()
+			}
+			field, rest = &e.Source, line[len("msgid "):]
+		case strings.HasPrefix(line, "msgstr "):
+			field, rest = &e.Target, line[len("msgstr "):]
+		case strings.HasPrefix(line, `"`) && field != nil:
+			rest = line
+		default:
+			return cat, fmt.Errorf("line %d: unknown %q", lineNo, line)
+		}
+		s, err := poUnquote(rest)
+		if err != nil {
+			return cat, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		*field += s
+	}
+	flush()
+	return cat, scanner.Err()
+}
+
+// XLIFF 1.2, only the used parts.
+type (
+	xliffDoc struct {
+		XMLName xml.Name  `xml:"urn:oasis:names:tc:xliff:document:1.2 xliff"`
+		Version string    `xml:"version,attr"`
+		File    xliffFile `xml:"file"`
+	}
+	xliffFile struct {
+		Original   string      `xml:"original,attr"`
+		SourceLang string      `xml:"source-language,attr"`
+		TargetLang string      `xml:"target-language,attr,omitempty"`
+		DataType   string      `xml:"datatype,attr"`
+		Units      []xliffUnit `xml:"body>trans-unit"`
+	}
+	xliffUnit struct {
+		ID     string      `xml:"id,attr"`
+		Source string      `xml:"source"`
+		Target xliffTarget `xml:"target"`
+	}
+	xliffTarget struct {
+		State string `xml:"state,attr,omitempty"`
+		Text  string `xml:",chardata"`
+	}
+)
+
+func writeXLIFF(w io.Writer, cat i18nCatalog) error {
+	doc := xliffDoc{Version: "1.2", File: xliffFile{Original: "forms", DataType: "plaintext",
+		SourceLang: cat.SourceLang, TargetLang: cat.TargetLang}}
+	for _, e := range cat.Entries {
+		u := xliffUnit{ID: e.Key, Source: e.Source, Target: xliffTarget{Text: e.Target}}
+		if e.Fuzzy {
+			u.Target.State = "needs-review-translation"
+		} else if e.Target == "" {
+			u.Target.State = "new"
+		}
+		doc.File.Units = append(doc.File.Units, u)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func readXLIFF(r io.Reader) (i18nCatalog, error) {
+	var doc xliffDoc
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return i18nCatalog{}, err
+	}
+	cat := i18nCatalog{SourceLang: doc.File.SourceLang, TargetLang: doc.File.TargetLang}
+	for _, u := range doc.File.Units {
+		cat.Entries = append(cat.Entries, i18nEntry{Key: u.ID, Source: u.Source, Target: u.Target.Text,
+			Fuzzy: strings.HasPrefix(u.Target.State, "needs-")})
+	}
+	return cat, nil
+}
+
+// i18nExtract collects the translatable strings of the forms into catalog fn.
+// The translations of an existing catalog are kept, the ones with changed source marked fuzzy.
+func i18nExtract(ctx context.Context, w io.Writer, converter Converter, fn, format, sourceLang, targetLang string, files []string) error {
+	cat := i18nCatalog{SourceLang: sourceLang, TargetLang: targetLang}
+	var old map[string]i18nEntry
+	if fn != "" && fn != "-" {
+		if prev, err := readCatalog(fn); err == nil {
+			old = prev.byKey()
+			if prev.TargetLang != "" && targetLang == "" {
+				cat.TargetLang = prev.TargetLang
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	var untranslated, stale int
+	seen := make(map[string]struct{})
+	for _, src := range files {
+		root, err := readForm(ctx, converter, src)
+		if err != nil {
+			return err
+		}
+		for _, u := range i18nUnits(root.Module()) {
+			if _, ok := seen[u.Key]; ok {
+				continue
+			}
+			seen[u.Key] = struct{}{}
+			e := i18nEntry{Key: u.Key, Source: u.Source}
+			if prev, ok := old[u.Key]; ok {
+				e.Target, e.Fuzzy = prev.Target, prev.Fuzzy
+				if prev.Source != u.Source && prev.Target != "" {
+					e.Fuzzy = true
+					fmt.Fprintf(w, "%s: stale: %q -> %q\n", u.Key, prev.Source, u.Source)
+				}
+			}
+			if e.Fuzzy {
+				stale++
+			} else if e.Target == "" {
+				untranslated++
+			}
+			cat.Entries = append(cat.Entries, e)
+		}
+	}
+	for k := range old {
+		if _, ok := seen[k]; !ok {
+			fmt.Fprintf(w, "%s: obsolete\n", k)
+		}
+	}
+	log.Printf("Extracted %d strings (%d untranslated, %d stale).", len(cat.Entries), untranslated, stale)
+	return writeCatalog(fn, format, cat)
+}
+
+// i18nApply writes the localized version of each form (with suffix),
+// reporting the untranslated and stale (source changed since translation) strings to w.
+// A form with such strings is written only with force, as it would be half translated.
+func i18nApply(ctx context.Context, w io.Writer, converter Converter, catFn, suffix string, force bool, files []string) error {
+	cat, err := readCatalog(catFn)
+	if err != nil {
+		return err
+	}
+	if suffix == "" {
+		suffix = "-i18n"
+		if cat.TargetLang != "" {
+			suffix = "-" + cat.TargetLang
+		}
+	}
+	entries := cat.byKey()
+	var problems int
+	for _, fn := range files {
+		root, err := readForm(ctx, converter, fn)
+		if err != nil {
+			return err
+		}
+		type nodeAttr struct {
+			Node *transform.Node
+			Attr string
+		}
+		messages := make(map[nodeAttr]map[int]string)
+		var missing int
+		for _, u := range i18nUnits(root.Module()) {
+			e, ok := entries[u.Key]
+			switch {
+			case !ok || e.Target == "":
+				fmt.Fprintf(w, "%s: %s: untranslated: %q\n", fn, u.Key, u.Source)
+				missing++
+				continue
+			case e.Fuzzy || e.Source != u.Source:
+				fmt.Fprintf(w, "%s: %s: stale: %q (translated %q)\n", fn, u.Key, u.Source, e.Source)
+				missing++
+				continue
+			}
+			if u.Msg < 0 {
+				u.Node.Set(u.Attr, i18nEscape(u.Node.Get(u.Attr), e.Target))
+				continue
+			}
+			na := nodeAttr{Node: u.Node, Attr: u.Attr}
+			if messages[na] == nil {
+				messages[na] = make(map[int]string)
+			}
+			messages[na][u.Msg] = e.Target
+		}
+		for na, m := range messages {
+			v := na.Node.Get(na.Attr)
+			var i int
+			na.Node.Set(na.Attr, rMessage.ReplaceAllStringFunc(v, func(s string) string {
+				t, ok := m[i]
+				i++
+				if !ok {
+					return s
+				}
+				lit := rMessage.FindStringSubmatchIndex(s)
+				return s[:lit[2]] + i18nEscape(v, strings.ReplaceAll(t, "'", "''")) + s[lit[3]:]
+			}))
+		}
+		problems += missing
+		dst := strings.TrimSuffix(fn, filepath.Ext(fn)) + suffix + filepath.Ext(fn)
+		if missing != 0 && !force {
+			log.Printf("Skip %q: %d untranslated or stale strings.", dst, missing)
+			continue
+		}
+		log.Printf("Write %q.", dst)
+		if err = writeForm(ctx, converter, dst, root); err != nil {
+			return err
+		}
+	}
+	if problems != 0 {
+		return fmt.Errorf("%d untranslated or stale strings", problems)
+	}
+	return nil
+}
+
+// i18nEscape escapes the line breaks of s as orig does (see transform.Text).
+func i18nEscape(orig, s string) string {
+	if !strings.Contains(orig, "&#10;") {
+		return s
+	}
+	return strings.NewReplacer("&", "&amp;", "\n", "&#10;").Replace(s)
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+	"github.com/google/go-cmp/cmp"
+)
+
+const i18nXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Module version="101020002" xmlns="http://xmlns.oracle.com/Forms">
+  <FormModule Name="EMP">
+    <Block Name="EMP">
+      <Item Name="ENAME" ItemType="Text Item" Prompt="Név" Hint="A dolgozó neve"/>
+      <Item Name="JOB" ItemType="List Item">
+        <ListItemElement Name="Elemző" Value="ANALYST"/>
+      </Item>
+      <Trigger Name="WHEN-VALIDATE-ITEM" TriggerText="if :emp.ename is null then&amp;#10;  message('Kötelező &amp;amp; ''fontos''');&amp;#10;  message(v_x);&amp;#10;end if;"/>
+    </Block>
+    <Graphics Name="T1" GraphicsType="Text">
+      <TextSegment Text="Első sor&amp;#10;második"/>
+    </Graphics>
+    <Window Name="W" Title="Dolgozók"/>
+  </FormModule>
+</Module>`
+
+var i18nTranslations = map[string]string{
+	"Név":                 "Name",
+	"A dolgozó neve":      "The employee's name",
+	"Elemző":              "Analyst",
+	"Kötelező & 'fontos'": "Required & 'important'",
+	"Első sor\nmásodik":   "First line\nsecond",
+	"Dolgozók":            "Employees",
+}
+
+func TestI18nRoundTrip(t *testing.T) {
+	for _, catName := range []string{"messages.po", "messages.xlf"} {
+		t.Run(catName, func(t *testing.T) {
+			dir := t.TempDir()
+			files := writeTestForms(t, dir, map[string]string{"emp": i18nXML})
+			catFn := filepath.Join(dir, catName)
+			format := "po"
+			if isXLIFF(catName) {
+				format = "xliff"
+			}
+			ctx := context.Background()
+			var buf bytes.Buffer
+			if err := i18nExtract(ctx, &buf, nil, catFn, format, "hu", "en", files); err != nil {
+				t.Fatalf("%+v", err)
+			}
+			cat, err := readCatalog(catFn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cat.SourceLang != "hu" || cat.TargetLang != "en" || len(cat.Entries) != len(i18nTranslations) {
+				t.Fatalf("got %+v", cat)
+			}
+
+			// an incomplete translation is not applied
+			dst := filepath.Join(dir, "emp-en.xml")
+			if err = i18nApply(ctx, &buf, nil, catFn, "", false, files); err == nil {
+				t.Error("untranslated: no error")
+			}
+			if _, err = os.Stat(dst); !os.IsNotExist(err) {
+				t.Fatalf("half translated %s is written: %v", dst, err)
+			}
+
+			for i, e := range cat.Entries {
+				cat.Entries[i].Target = i18nTranslations[e.Source]
+			}
+			if err = writeCatalog(catFn, format, cat); err != nil {
+				t.Fatal(err)
+			}
+			buf.Reset()
+			if err = i18nApply(ctx, &buf, nil, catFn, "", false, files); err != nil {
+				t.Fatalf("%+v\n%s", err, buf.String())
+			}
+			root, err := readForm(ctx, nil, dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, u := range i18nUnits(root.Module()) {
+				got = append(got, u.Source)
+			}
+			want := []string{"Name", "The employee's name", "Analyst", "Required & 'important'", "First line\nsecond", "Employees"}
+			if d := cmp.Diff(want, got); d != "" {
+				t.Error(d)
+			}
+			if s := transform.Text(root.Module().Find("Trigger")[0].Get("TriggerText")); !strings.Contains(s,
+				"message('Required & ''important''');\n  message(v_x);") {
+				t.Errorf("got %q", s)
+			}
+
+			// the changed source makes the translation stale
+			src, err := os.ReadFile(files[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = os.WriteFile(files[0], bytes.Replace(src, []byte(`Title="Dolgozók"`), []byte(`Title="Munkatársak"`), 1), 0644); err != nil {
+				t.Fatal(err)
+			}
+			buf.Reset()
+			if err = i18nExtract(ctx, &buf, nil, catFn, format, "hu", "", files); err != nil {
+				t.Fatalf("%+v", err)
+			}
+			if !strings.Contains(buf.String(), `FormModule[EMP]/Window[W]#Title: stale: "Dolgozók" -> "Munkatársak"`) {
+				t.Errorf("got %s", buf.String())
+			}
+			if err = os.Remove(dst); err != nil {
+				t.Fatal(err)
+			}
+			buf.Reset()
+			if err = i18nApply(ctx, &buf, nil, catFn, "", false, files); err == nil || !strings.Contains(buf.String(), "stale") {
+				t.Errorf("stale: got %v\n%s", err, buf.String())
+			}
+			if _, err = os.Stat(dst); !os.IsNotExist(err) {
+				t.Fatalf("half translated %s is written: %v", dst, err)
+			}
+			if err = i18nApply(ctx, &buf, nil, catFn, "", true, files); err == nil {
+				t.Error("stale, forced: no error")
+			}
+			if _, err = os.Stat(dst); err != nil {
+				t.Errorf("forced: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// javaConfig is how the JDAPI helper is started.
+type javaConfig struct {
+	// Helper is the command started instead of java unosoft.forms.Serve
+	// (e.g. cmd/fakejdapi), with the address to listen on appended.
+	Helper []string `json:"helper,omitempty"`
+	// Java is the java binary ("java" from PATH if empty).
+	Java string `json:"java,omitempty"`
+	// OracleHome is the Forms installation ($ORACLE_HOME, or searched under /oracle if empty).
+	OracleHome string `json:"oracleHome,omitempty"`
+	// Jars are the JDAPI jars, relative to OracleHome.
+	Jars []string `json:"jars,omitempty"`
+	// Classpath are extra classpath entries.
+	Classpath  []string          `json:"classpath,omitempty"`
+	JVMOptions []string          `json:"jvmOptions,omitempty"`
+	Env        map[string]string `json:"env,omitempty"`
+	// StartupTimeout is the maximum wait for the helper to report that it is ready.
+	StartupTimeout duration `json:"startupTimeout,omitempty"`
+	// Transport is "unix" (the default: a Unix domain socket in a private directory,
+	// or TCP if the helper cannot listen on it), or "tcp" (an ephemeral loopback port).
+	Transport string `json:"transport,omitempty"`
+
+	// MinWorkers helpers are kept running, even when idle.
+	MinWorkers int `json:"minWorkers,omitempty"`
+	// MaxWorkers is the maximum number of helpers (the concurrency if 0).
+	MaxWorkers int `json:"maxWorkers,omitempty"`
+	// MaxJobs is the number of conversions after a helper is recycled, as JDAPI leaks native memory.
+	MaxJobs int `json:"maxJobs,omitempty"`
+	// IdleTimeout is the time after an unused helper is stopped (above MinWorkers).
+	IdleTimeout duration `json:"idleTimeout,omitempty"`
+	// PingInterval is the period of the health checks of the idle helpers.
+	PingInterval duration `json:"pingInterval,omitempty"`
+}
+
+// The defaults of javaConfig.
+const (
+	// DefaultStartupTimeout is long: connecting to the database may be slow.
+	DefaultStartupTimeout = 2 * time.Minute
+	DefaultMaxJobs        = 100
+	DefaultIdleTimeout    = 5 * time.Minute
+	DefaultPingInterval   = 30 * time.Second
+)
+
+// duration is a time.Duration, as string ("90s") in JSON.
+type duration time.Duration
+
+// or returns the duration, or def if it is not set.
+func (d duration) or(def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return time.Duration(d)
+}
+
+func (d duration) MarshalJSON() ([]byte, error) { return json.Marshal(time.Duration(d).String()) }
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	*d = duration(v)
+	return err
+}
+
+// javaConfigFile is the JSON config file: the defaults and the profiles overriding them.
+type javaConfigFile struct {
+	javaConfig
+	Profiles map[string]javaConfig `json:"profiles,omitempty"`
+}
+
+// javaProfiles are the built-in settings of our hosts, the config file may override them.
+var javaProfiles = map[string]javaConfig{
+	"11g": {
+		OracleHome: "/oracle/mw11gR1/fr11gR2",
+		Jars:       []string{"jlib/frmjdapi.jar", "jlib/frmxmltools.jar", "lib/xmlparserv2.jar"},
+	},
+	"12c": {
+		Java:       "/oracle/fmw12c/product/jdk/bin/java",
+		OracleHome: "/oracle/fmw12c/product",
+		Jars:       []string{"jlib/frmjdapi.jar", "jlib/frmxmltools.jar", "oracle_common/modules/oracle.xdk/xmlparserv2.jar"},
+	},
+}
+
+// override the settings with the non-empty ones of o.
+func (c javaConfig) override(o javaConfig) javaConfig {
+	if len(o.Helper) != 0 {
+		c.Helper = o.Helper
+	}
+	if o.Java != "" {
+		c.Java = o.Java
+	}
+	if o.OracleHome != "" {
+		c.OracleHome = o.OracleHome
+	}
+	if len(o.Jars) != 0 {
+		c.Jars = o.Jars
+	}
+	if o.StartupTimeout != 0 {
+		c.StartupTimeout = o.StartupTimeout
+	}
+	if o.Transport != "" {
+		c.Transport = o.Transport
+	}
+	if o.MinWorkers != 0 {
+		c.MinWorkers = o.MinWorkers
+	}
+	if o.MaxWorkers != 0 {
+		c.MaxWorkers = o.MaxWorkers
+	}
+	if o.MaxJobs != 0 {
+		c.MaxJobs = o.MaxJobs
+	}
+	if o.IdleTimeout != 0 {
+		c.IdleTimeout = o.IdleTimeout
+	}
+	if o.PingInterval != 0 {
+		c.PingInterval = o.PingInterval
+	}
+	c.Classpath = append(c.Classpath[:len(c.Classpath):len(c.Classpath)], o.Classpath...)
+	c.JVMOptions = append(c.JVMOptions[:len(c.JVMOptions):len(c.JVMOptions)], o.JVMOptions...)
+	if len(o.Env) != 0 {
+		env := make(map[string]string, len(c.Env)+len(o.Env))
+		for k, v := range c.Env {
+			env[k] = v
+		}
+		for k, v := range o.Env {
+			env[k] = v
+		}
+		c.Env = env
+	}
+	return c
+}
+
+// readJavaConfig returns the settings of the profile: the built-in profile,
+// overridden by the defaults then the profile of the config file (if fn is not empty).
+func readJavaConfig(fn, profile string) (javaConfig, error) {
+	var file javaConfigFile
+	if fn != "" {
+		b, err := os.ReadFile(fn)
+		if err != nil {
+			return javaConfig{}, err
+		}
+		if err = json.Unmarshal(b, &file); err != nil {
+			return javaConfig{}, fmt.Errorf("parse %q: %w", fn, err)
+		}
+	}
+	c, builtin := javaProfiles[profile]
+	c = c.override(file.javaConfig)
+	if profile != "" {
+		p, ok := file.Profiles[profile]
+		if !ok && !builtin {
+			return c, fmt.Errorf("unknown profile %q", profile)
+		}
+		c = c.override(p)
+	}
+	return c, nil
+}
+
+// parseEnv parses the KEY=VALUE list.
+func parseEnv(ss []string) (map[string]string, error) {
+	if len(ss) == 0 {
+		return nil, nil
+	}
+	m := make(map[string]string, len(ss))
+	for _, s := range ss {
+		k, v, ok := strings.Cut(s, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("%q: KEY=VALUE is needed", s)
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+// resolve fills the missing OracleHome and Jars.
+func (c javaConfig) resolve() (javaConfig, error) {
+	if len(c.Helper) != 0 {
+		return c, nil
+	}
+	if c.Java == "" {
+		c.Java = "java"
+	}
+	if c.OracleHome == "" {
+		c.OracleHome = os.Getenv("ORACLE_HOME")
+	}
+	if c.OracleHome == "" {
+		cmd := exec.Command("find", "/oracle", "-type", "f", "-name", "frmjdapi.jar")
+		b, err := cmd.Output()
+		if len(b) == 0 {
+			return c, fmt.Errorf("ORACLE_HOME is not set, and %v: %w", cmd.Args, err)
+		}
+		c.OracleHome = filepath.Dir(filepath.Dir(string(bytes.SplitN(b, []byte("\n"), 2)[0])))
+	}
+	if len(c.Jars) == 0 {
+		c.Jars = []string{"jlib/frmjdapi.jar", "jlib/frmxmltools.jar", "oracle_common/modules/oracle.xdk/xmlparserv2.jar"}
+		if _, err := os.Stat(filepath.Join(c.OracleHome, c.Jars[2])); err != nil {
+			c.Jars[2] = "lib/xmlparserv2.jar"
+		}
+	}
+	return c, nil
+}
+
+// jarPaths returns the jars with OracleHome and the extra classpath entries.
+func (c javaConfig) jarPaths() []string {
+	paths := make([]string, 0, len(c.Jars)+len(c.Classpath))
+	for _, fn := range c.Jars {
+		if !filepath.IsAbs(fn) {
+			fn = filepath.Join(c.OracleHome, fn)
+		}
+		paths = append(paths, fn)
+	}
+	return append(paths, c.Classpath...)
+}
+
+// validate reports the missing java binary and jars.
+func (c javaConfig) validate() error {
+	switch c.Transport {
+	case "", "unix", "tcp":
+	default:
+		return fmt.Errorf("transport %q: unix or tcp is needed", c.Transport)
+	}
+	if len(c.Helper) != 0 {
+		if _, err := exec.LookPath(c.Helper[0]); err != nil {
+			return fmt.Errorf("helper: %w", err)
+		}
+		return nil
+	}
+	var missing []string
+	if _, err := exec.LookPath(c.Java); err != nil {
+		missing = append(missing, c.Java)
+	}
+	for _, fn := range c.jarPaths() {
+		if strings.HasSuffix(fn, "*") { // java's dir/* wildcard
+			fn = filepath.Dir(fn)
+		}
+		if _, err := os.Stat(fn); err != nil {
+			missing = append(missing, fn)
+		}
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("java helper (ORACLE_HOME=%q): missing %s", c.OracleHome, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// environ returns the environment of the helper: ours, with the Forms settings and the overrides.
+func (c javaConfig) environ(display, formsLibPath string) []string {
+	env := append(os.Environ(),
+		"DISPLAY="+display,
+		"TERM=xterm",
+		"FORMS_PATH="+formsLibPath,
+	)
+	if c.OracleHome != "" {
+		env = append(env,
+			"PATH="+filepath.Join(c.OracleHome, "bin")+":/usr/lib64/qt-3.3/bin:"+os.Getenv("PATH"),
+			"ORACLE_HOME="+c.OracleHome,
+			"LD_LIBRARY_PATH="+filepath.Join(c.OracleHome, "bin")+":"+filepath.Join(c.OracleHome, "lib")+":"+os.Getenv("LD_LIBRARY_PATH"),
+		)
+	}
+	keys := make([]string, 0, len(c.Env))
+	for k := range c.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, k+"="+c.Env[k])
+	}
+	return env
+}
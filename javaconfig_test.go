@@ -0,0 +1,108 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJavaConfig(t *testing.T) {
+	dir := t.TempDir()
+	fn := filepath.Join(dir, "java.json")
+	if err := os.WriteFile(fn, []byte(`{
+	"jvmOptions": ["-Xmx1g"],
+	"env": {"NLS_LANG": "HUNGARIAN_HUNGARY.EE8ISO8859P2", "A": "a"},
+	"profiles": {
+		"12c": {"oracleHome": "`+dir+`", "jvmOptions": ["-XX:+UseG1GC"], "env": {"A": "b"}},
+		"test": {"java": "`+os.Args[0]+`", "oracleHome": "`+dir+`", "jars": ["a.jar", "b.jar"]}
+	}
+}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := readJavaConfig(fn, "12c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := javaProfiles["12c"]
+	want.OracleHome = dir
+	want.JVMOptions = []string{"-Xmx1g", "-XX:+UseG1GC"}
+	want.Env = map[string]string{"NLS_LANG": "HUNGARIAN_HUNGARY.EE8ISO8859P2", "A": "b"}
+	if d := cmp.Diff(want, c, cmp.AllowUnexported(javaConfig{})); d != "" {
+		t.Errorf("12c (-want +got):\n%s", d)
+	}
+	if _, err = readJavaConfig(fn, "13c"); err == nil {
+		t.Error("unknown profile: no error")
+	}
+
+	c, err = readJavaConfig(fn, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "a.jar"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	c = c.override(javaConfig{Classpath: []string{filepath.Join(dir, "extra.jar")}})
+	err = c.validate()
+	if err == nil {
+		t.Fatal("missing jars: no error")
+	}
+	t.Log(err)
+	for _, s := range []string{"b.jar", "extra.jar"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("%q is not reported", s)
+		}
+	}
+	if strings.Contains(err.Error(), "/a.jar") {
+		t.Error("existing a.jar is reported")
+	}
+
+	env := c.override(javaConfig{Env: map[string]string{"DISPLAY": ":99"}}).environ(":0", "")
+	// exec.Cmd uses the last value
+	var display string
+	for _, e := range env {
+		if v, ok := strings.CutPrefix(e, "DISPLAY="); ok {
+			display = v
+		}
+	}
+	if display != ":99" {
+		t.Errorf("DISPLAY is not overridden: %q", display)
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	for conn, want := range map[string]string{
+		"scott/tiger@db":       "scott/***@db",
+		"scott/ti@ger@db:1521": "scott/***@db:1521",
+		"scott/tiger":          "scott/***",
+		"/@db":                 "/***@db",
+		"":                     "",
+	} {
+		args := []string{"java", "-Dforms.db.conn=" + conn, "unosoft.forms.Serve"}
+		got := redactArgs(args)
+		if d := cmp.Diff([]string{"java", "-Dforms.db.conn=" + want, "unosoft.forms.Serve"}, got); d != "" {
+			t.Errorf("%q: %s", conn, d)
+		}
+		if args[1] != "-Dforms.db.conn="+conn {
+			t.Errorf("%q: the args are modified", conn)
+		}
+	}
+}
@@ -0,0 +1,428 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// pingTimeout is the maximum wait for a health check answer.
+const pingTimeout = 10 * time.Second
+
+// javaPool is the pool of the JDAPI helpers (JVMs).
+//
+// Helpers are started on demand, up to MaxWorkers, and stopped when they have been idle
+// for IdleTimeout (keeping MinWorkers), after MaxJobs conversions, after a failed
+// request or health check, and when they exit (crash).
+type javaPool struct {
+	ctx   context.Context
+	start func(context.Context) (HTTPClient, error)
+
+	min, max, maxJobs         int
+	idleTimeout, pingInterval time.Duration
+
+	mu       sync.Mutex
+	workers  []*javaWorker
+	starting int
+	waiting  int
+	nextID   int
+	// changed is closed (and replaced) when a worker is released or removed.
+	changed chan struct{}
+	counts  poolCounts
+}
+
+// javaWorker is a running helper.
+type javaWorker struct {
+	HTTPClient
+	ID                int
+	Jobs              int
+	Busy              bool
+	Started, LastUsed time.Time
+	// removed is set when the pool stops the helper (so its exit is not a crash).
+	removed bool
+}
+
+// poolCounts are the cumulative counters of the pool.
+type poolCounts struct {
+	Started     int `json:"started"`
+	StartFailed int `json:"startFailed"`
+	Recycled    int `json:"recycled"`
+	Failed      int `json:"failed"`
+	PingFailed  int `json:"pingFailed"`
+	Idled       int `json:"idled"`
+	Crashed     int `json:"crashed"`
+	Cancelled   int `json:"cancelled"`
+}
+
+// poolStats is the observable state of the pool.
+type poolStats struct {
+	Workers  []workerStats `json:"workers"`
+	Starting int           `json:"starting"`
+	Waiting  int           `json:"waiting"`
+	poolCounts
+}
+
+type workerStats struct {
+	ID       int       `json:"id"`
+	PID      int       `json:"pid"`
+	Addr     string    `json:"addr"`
+	Jobs     int       `json:"jobs"`
+	Busy     bool      `json:"busy"`
+	Started  time.Time `json:"started"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// newJavaPool returns a pool of helpers started by start, maintained until ctx is done.
+func newJavaPool(ctx context.Context, c javaConfig, concurrency int, start func(context.Context) (HTTPClient, error)) *javaPool {
+	p := javaPool{
+		ctx: ctx, start: start,
+		min: c.MinWorkers, max: c.MaxWorkers, maxJobs: c.MaxJobs,
+		idleTimeout:  c.IdleTimeout.or(DefaultIdleTimeout),
+		pingInterval: c.PingInterval.or(DefaultPingInterval),
+		changed:      make(chan struct{}),
+	}
+	if p.max <= 0 {
+		p.max = concurrency
+	}
+	if p.min > p.max {
+		p.min = p.max
+	}
+	if p.maxJobs == 0 {
+		p.maxJobs = DefaultMaxJobs
+	}
+	p.mu.Lock()
+	p.fill()
+	p.mu.Unlock()
+	go p.maintain()
+	return &p
+}
+
+// get returns an idle worker, or starts a new one if the pool is not full,
+// or waits for one to be released.
+func (p *javaPool) get(ctx context.Context) (*javaWorker, error) {
+	p.mu.Lock()
+	for {
+		// the most recently used one, so the others may time out
+		var w *javaWorker
+		for _, x := range p.workers {
+			if !x.Busy && (w == nil || x.LastUsed.After(w.LastUsed)) {
+				w = x
+			}
+		}
+		if w != nil {
+			w.Busy = true
+			w.Jobs++
+			p.mu.Unlock()
+			return w, nil
+		}
+		if len(p.workers)+p.starting < p.max {
+			p.starting++
+			p.mu.Unlock()
+			return p.startBusy(ctx)
+		}
+		changed := p.changed
+		p.waiting++
+		p.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			p.mu.Lock()
+			p.waiting--
+			p.mu.Unlock()
+			return nil, ctx.Err()
+		case <-changed:
+		}
+		p.mu.Lock()
+		p.waiting--
+	}
+}
+
+// startBusy starts a worker for the caller. The start is not bound to ctx:
+// if the caller gives up, the worker is released to the pool.
+func (p *javaPool) startBusy(ctx context.Context) (*javaWorker, error) {
+	type result struct {
+		w   *javaWorker
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		w, err := p.startWorker(true)
+		ch <- result{w: w, err: err}
+	}()
+	select {
+	case res := <-ch:
+		return res.w, res.err
+	case <-ctx.Done():
+		go func() {
+			if res := <-ch; res.w != nil {
+				p.release(res.w, false, false)
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// startWorker starts a worker, and adds it to the pool.
+// p.starting must have been incremented by the caller.
+func (p *javaPool) startWorker(busy bool) (*javaWorker, error) {
+	cl, err := p.start(p.ctx)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.starting--
+	defer p.notify()
+	if err != nil {
+		p.counts.StartFailed++
+		return nil, err
+	}
+	now := time.Now()
+	p.nextID++
+	w := &javaWorker{HTTPClient: cl, ID: p.nextID, Busy: busy, Started: now, LastUsed: now}
+	if busy {
+		w.Jobs++
+	}
+	p.workers = append(p.workers, w)
+	p.counts.Started++
+	go p.watch(w)
+	return w, nil
+}
+
+// put releases the worker after a conversion, recycling it when the request failed
+// or it has done MaxJobs conversions.
+func (p *javaPool) put(w *javaWorker, failed bool) { p.release(w, failed, true) }
+
+func (p *javaPool) release(w *javaWorker, failed, used bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	defer p.notify()
+	w.Busy = false
+	if used {
+		w.LastUsed = time.Now()
+	}
+	switch {
+	case w.removed:
+	case failed:
+		if used { // the health check failures are counted by maintain
+			p.counts.Failed++
+		}
+		p.remove(w, "failed")
+	case p.maxJobs > 0 && w.Jobs >= p.maxJobs:
+		p.counts.Recycled++
+		p.remove(w, "recycled")
+	}
+	p.fill()
+}
+
+// abort stops the worker whose request has been cancelled, as it may be still converting.
+func (p *javaPool) abort(w *javaWorker) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	defer p.notify()
+	w.Busy = false
+	p.counts.Cancelled++
+	p.remove(w, "cancelled")
+	p.fill()
+}
+
+// ready waits till there is a worker, starting one if none is starting.
+func (p *javaPool) ready(ctx context.Context) error {
+	p.mu.Lock()
+	for len(p.workers) == 0 {
+		if p.starting == 0 {
+			p.starting++
+			p.mu.Unlock()
+			errCh := make(chan error, 1)
+			go func() {
+				_, err := p.startWorker(false)
+				errCh <- err
+			}()
+			select {
+			case err := <-errCh:
+				return err
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		changed := p.changed
+		p.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+		p.mu.Lock()
+	}
+	p.mu.Unlock()
+	return nil
+}
+
+// watch detects the exit of the worker's process.
+func (p *javaPool) watch(w *javaWorker) {
+	<-w.Exited
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if w.removed {
+		return
+	}
+	log.Printf("helper %d (pid=%d) crashed: stderr:\n%s", w.ID, w.PID, w.ErrBuf.String())
+	p.counts.Crashed++
+	p.remove(w, "crashed")
+	p.notify()
+	p.fill()
+}
+
+// remove the worker from the pool, and stop it. p.mu must be held.
+func (p *javaPool) remove(w *javaWorker, reason string) {
+	if w.removed {
+		return
+	}
+	w.removed = true
+	for i, x := range p.workers {
+		if x == w {
+			p.workers = append(p.workers[:i], p.workers[i+1:]...)
+			break
+		}
+	}
+	log.Printf("stop helper %d (pid=%d, jobs=%d): %s", w.ID, w.PID, w.Jobs, reason)
+	w.Close()
+}
+
+// notify the waiters of get. p.mu must be held.
+func (p *javaPool) notify() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// fill starts workers in the background, up to MinWorkers. p.mu must be held.
+func (p *javaPool) fill() {
+	if p.ctx.Err() != nil {
+		return
+	}
+	for n := len(p.workers) + p.starting; n < p.min; n++ {
+		p.starting++
+		go func() {
+			if _, err := p.startWorker(false); err != nil {
+				log.Printf("start: %v", err)
+			}
+		}()
+	}
+}
+
+// maintain stops the workers idle for too long, and checks the health of the others, periodically.
+func (p *javaPool) maintain() {
+	ticker := time.NewTicker(min(p.pingInterval, p.idleTimeout))
+	defer ticker.Stop()
+	var lastPing time.Time
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		now := time.Now()
+		ping := now.Sub(lastPing) >= p.pingInterval
+		if ping {
+			lastPing = now
+		}
+		var toPing []*javaWorker
+		p.mu.Lock()
+		for _, w := range append([]*javaWorker(nil), p.workers...) {
+			if w.Busy {
+				continue
+			}
+			if now.Sub(w.LastUsed) >= p.idleTimeout && len(p.workers) > p.min {
+				p.counts.Idled++
+				p.remove(w, "idle")
+			} else if ping {
+				w.Busy = true
+				toPing = append(toPing, w)
+			}
+		}
+		p.fill()
+		p.mu.Unlock()
+
+		for _, w := range toPing {
+			err := w.ping(p.ctx)
+			if err != nil {
+				log.Printf("ping helper %d (pid=%d): %v", w.ID, w.PID, err)
+				p.mu.Lock()
+				p.counts.PingFailed++
+				p.mu.Unlock()
+			}
+			p.release(w, err != nil, false)
+		}
+	}
+}
+
+// ping checks whether the helper answers. Any HTTP response will do:
+// the helper is single threaded, so a hung conversion would block this, too.
+func (w *javaWorker) ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "HEAD", w.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := w.HTTPClient.Client.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// Stats returns the state of the pool (empty if there is no pool, as the config is invalid).
+func (p *javaPool) Stats() poolStats {
+	if p == nil {
+		return poolStats{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := poolStats{Starting: p.starting, Waiting: p.waiting, poolCounts: p.counts,
+		Workers: make([]workerStats, 0, len(p.workers))}
+	for _, w := range p.workers {
+		st.Workers = append(st.Workers, workerStats{
+			ID: w.ID, PID: w.PID, Addr: w.Addr, Jobs: w.Jobs, Busy: w.Busy,
+			Started: w.Started, LastUsed: w.LastUsed,
+		})
+	}
+	return st
+}
+
+// ServeHTTP writes the Stats as JSON.
+func (p *javaPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(p.Stats())
+}
+
+// poolBody releases the worker when the response body is closed.
+type poolBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *poolBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
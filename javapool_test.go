@@ -0,0 +1,210 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func newTestPool(t *testing.T, c javaConfig) (context.Context, *javaRunner) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	t.Cleanup(cancel)
+	if len(c.Helper) == 0 {
+		c.Helper = []string{os.Args[0]}
+	}
+	jr := newJavaRunner(ctx, "", "", "", c, 1, 2)
+	if jr.err != nil {
+		t.Fatal(jr.err)
+	}
+	return ctx, jr
+}
+
+// waitStats waits until the pool's state satisfies ok.
+func waitStats(t *testing.T, ctx context.Context, jr *javaRunner, ok func(poolStats) bool) poolStats {
+	t.Helper()
+	for {
+		st := jr.PoolStats()
+		if ok(st) {
+			return st
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("%+v: %v", st, ctx.Err())
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+func TestPoolRecycle(t *testing.T) {
+	ctx, jr := newTestPool(t, javaConfig{MaxJobs: 2})
+	dir := t.TempDir()
+	src, _ := testModule(t, dir, "a")
+	for i := range 5 {
+		if err := jr.Convert(ctx, io.Discard, mustOpen(t, src), "application/x-oracle-forms"); err != nil {
+			t.Fatalf("%d. %+v", i, err)
+		}
+	}
+	st := jr.PoolStats()
+	t.Logf("%+v", st)
+	if st.Started != 3 || st.Recycled != 2 || st.Crashed != 0 {
+		t.Errorf("5 jobs, 2 jobs per helper: got %+v", st)
+	}
+}
+
+func TestPoolCrash(t *testing.T) {
+	ctx, jr := newTestPool(t, javaConfig{MinWorkers: 1})
+	st := waitStats(t, ctx, jr, func(st poolStats) bool { return len(st.Workers) == 1 })
+	pid := st.Workers[0].PID
+	if err := syscall.Kill(pid, syscall.SIGKILL); err != nil {
+		t.Fatal(err)
+	}
+	// crashed, and replaced to keep MinWorkers
+	st = waitStats(t, ctx, jr, func(st poolStats) bool {
+		return st.Crashed == 1 && len(st.Workers) == 1 && st.Workers[0].PID != pid
+	})
+	t.Logf("%+v", st)
+	if err := jr.ConvertFiles(ctx, filepath.Join(t.TempDir(), "a.xml"), mustTestModule(t)); err != nil {
+		t.Fatalf("%+v", err)
+	}
+}
+
+func TestPoolIdle(t *testing.T) {
+	ctx, jr := newTestPool(t, javaConfig{
+		IdleTimeout: duration(200 * time.Millisecond), PingInterval: duration(50 * time.Millisecond)})
+	if err := jr.ConvertFiles(ctx, filepath.Join(t.TempDir(), "a.xml"), mustTestModule(t)); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if st := jr.PoolStats(); len(st.Workers) != 1 {
+		t.Fatalf("no worker after a conversion: %+v", st)
+	}
+	st := waitStats(t, ctx, jr, func(st poolStats) bool { return len(st.Workers) == 0 })
+	if st.Idled != 1 {
+		t.Errorf("got %+v", st)
+	}
+}
+
+func TestPoolPing(t *testing.T) {
+	// ready, but not listening
+	ctx, jr := newTestPool(t, javaConfig{Helper: []string{"sh", "-c", "echo READY 127.0.0.1:1; sleep 60"},
+		MinWorkers: 1, PingInterval: duration(50 * time.Millisecond)})
+	st := waitStats(t, ctx, jr, func(st poolStats) bool { return st.PingFailed >= 1 && st.Started >= 2 })
+	t.Logf("%+v", st)
+}
+
+func TestPoolMaxWorkers(t *testing.T) {
+	ctx, jr := newTestPool(t, javaConfig{MaxWorkers: 1})
+	w, err := jr.pool.get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shortCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	if _, err = jr.pool.get(shortCtx); err == nil {
+		t.Fatal("got a second worker")
+	}
+	done := make(chan error, 1)
+	go func() {
+		w2, err := jr.pool.get(ctx)
+		if err == nil && w2 != w {
+			t.Errorf("got another worker: %d", w2.ID)
+		}
+		done <- err
+	}()
+	waitStats(t, ctx, jr, func(st poolStats) bool { return st.Waiting == 1 })
+	jr.pool.put(w, false)
+	if err = <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustOpen(t *testing.T, fn string) *os.File {
+	fh, err := os.Open(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fh.Close() })
+	return fh
+}
+
+func mustTestModule(t *testing.T) string {
+	fn, _ := testModule(t, t.TempDir(), "a")
+	return fn
+}
+
+func TestPoolCancel(t *testing.T) {
+	ctx, jr := newTestPool(t, javaConfig{Env: map[string]string{fakeDelayEnv: "30s"}})
+	if err := jr.Ready(ctx); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	st := jr.PoolStats()
+	if len(st.Workers) != 1 {
+		t.Fatalf("not ready: %+v", st)
+	}
+	pid := st.Workers[0].PID
+
+	cctx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := jr.ConvertFiles(cctx, filepath.Join(t.TempDir(), "a.xml"), mustTestModule(t))
+	if err == nil {
+		t.Fatal("no error")
+	}
+	t.Log(err)
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("cancellation took %s", d)
+	}
+	// the helper is stopped
+	st = waitStats(t, ctx, jr, func(st poolStats) bool { return st.Cancelled == 1 && len(st.Workers) == 0 })
+	if st.Crashed != 0 {
+		t.Errorf("got %+v", st)
+	}
+	for syscall.Kill(pid, 0) == nil {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("helper %d is still running", pid)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// TestPoolJava starts MinWorkers JVMs concurrently (run with -race): the classes are extracted once.
+func TestPoolJava(t *testing.T) {
+	home := t.TempDir()
+	if err := os.WriteFile(filepath.Join(home, "a.jar"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	t.Cleanup(cancel)
+	// the fake helper ignores the JVM arguments
+	jr := newJavaRunner(ctx, "", "", "", javaConfig{Java: os.Args[0], OracleHome: home, Jars: []string{"a.jar"}, MinWorkers: 2}, 1, 2)
+	if jr.err != nil {
+		t.Fatal(jr.err)
+	}
+	t.Cleanup(func() { os.RemoveAll(jr.classes) })
+	if des, err := os.ReadDir(jr.classes); err != nil || len(des) == 0 {
+		t.Fatalf("classes in %q: %v %v", jr.classes, des, err)
+	}
+	waitStats(t, ctx, jr, func(st poolStats) bool { return len(st.Workers) == 2 })
+	if err := jr.ConvertFiles(ctx, filepath.Join(t.TempDir(), "a.xml"), mustTestModule(t)); err != nil {
+		t.Fatalf("%+v", err)
+	}
+}
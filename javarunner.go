@@ -16,9 +16,11 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"embed"
+	"fmt"
 	"io"
 	"io/fs"
 	"log"
@@ -30,6 +32,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -44,20 +47,67 @@ import (
 //go:embed classes
 var classesFS embed.FS
 
+// stopTimeout is the wait for a helper to exit after SIGTERM, before it is killed.
+const stopTimeout = 5 * time.Second
+
+// maxUnixPath is the length limit of a Unix domain socket's path (sun_path, 108 bytes on Linux).
+const maxUnixPath = 108
+
 type javaRunner struct {
-	DbConn, Display, FormsLibPath  string
-	MaxRetries                     int
-	classes, classpath, oracleHome string
+	DbConn, Display, FormsLibPath string
+	MaxRetries                    int
+	Config                        javaConfig
 
-	newClients  chan HTTPClient
-	freeClients chan HTTPClient
+	// err is the invalid Config, reported instead of starting anything.
+	err error
+	// classes is the dir of the extracted helper classes, classpath its JVM classpath.
+	// Both are set by newJavaRunner, and read-only after.
+	classes, classpath string
+
+	pool *javaPool
 }
 
 type HTTPClient struct {
 	*retryablehttp.Client
 	Cancel context.CancelFunc
 	URL    string
-	ErrBuf *strings.Builder
+	// Addr is the address reported by the helper: host:port or unix:/path.
+	Addr   string
+	ErrBuf *lockedBuffer
+	PID    int
+	// Exited is closed when the helper process exits.
+	Exited <-chan struct{}
+}
+
+// lockedBuffer is the stderr of the helper, written by the exec.Cmd's goroutine.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (lb *lockedBuffer) Write(p []byte) (int, error) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return lb.buf.Write(p)
+}
+
+func (lb *lockedBuffer) String() string {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return lb.buf.String()
+}
+
+func (lb *lockedBuffer) Len() int {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return lb.buf.Len()
+}
+
+// Since returns what has been written after the first n bytes.
+func (lb *lockedBuffer) Since(n int) string {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return lb.buf.String()[n:]
 }
 
 func (cl HTTPClient) Close() error {
@@ -67,7 +117,10 @@ func (cl HTTPClient) Close() error {
 	return nil
 }
 
-func newJavaRunner(ctx context.Context, conn, formsLibPath, display string,
+// newJavaRunner returns a javaRunner starting the helpers as configured.
+// The configuration is validated first: when it is invalid, no JVM is started,
+// and all conversions return the error.
+func newJavaRunner(ctx context.Context, conn, formsLibPath, display string, config javaConfig,
 	maxRetries, concurrency int) *javaRunner {
 	if concurrency <= 1 {
 		concurrency = 2
@@ -77,27 +130,107 @@ func newJavaRunner(ctx context.Context, conn, formsLibPath, display string,
 	}
 	jr := javaRunner{
 		DbConn: conn, FormsLibPath: formsLibPath, Display: display,
-		newClients:  make(chan HTTPClient, concurrency/2),
-		freeClients: make(chan HTTPClient, concurrency/2),
-		MaxRetries:  maxRetries,
+		MaxRetries: maxRetries,
 	}
-	go func() {
-		for {
-			cl, err := jr.start(ctx)
-			if err != nil {
-				log.Printf("start: %v", err)
-				time.Sleep(3 * time.Second)
-			}
-			select {
-			case <-ctx.Done():
-				return
-			case jr.newClients <- cl:
-			}
+	if jr.Config, jr.err = config.resolve(); jr.err == nil {
+		jr.err = jr.Config.validate()
+	}
+	if jr.err == nil && len(jr.Config.Helper) == 0 {
+		// once, before the pool starts the helpers concurrently
+		if jr.classes, jr.err = extractClasses(); jr.err == nil {
+			jr.classpath = strings.Join(append([]string{jr.classes}, jr.Config.jarPaths()...), ":")
+			log.Println("classpath:", jr.classpath)
 		}
-	}()
+	}
+	if jr.err != nil {
+		return &jr
+	}
+	jr.pool = newJavaPool(ctx, jr.Config, concurrency, jr.start)
 	return &jr
 }
 
+// extractClasses writes the embedded helper classes into a new temp dir, returning its path.
+func extractClasses() (string, error) {
+	dir, err := os.MkdirTemp("", "forms2xml-classes-")
+	if err != nil {
+		return "", errors.Wrap(err, "create temp dir for classes")
+	}
+	FS, err := fs.Sub(classesFS, "classes")
+	if err != nil {
+		return dir, err
+	}
+	// all of them, Serve has inner classes
+	return dir, fs.WalkDir(FS, ".", func(fn string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(fn, ".class") {
+			return err
+		}
+		b, err := fs.ReadFile(FS, fn)
+		if err != nil {
+			return errors.Wrap(err, "read "+fn)
+		}
+		fn = filepath.Join(dir, fn)
+		os.MkdirAll(filepath.Dir(fn), 0755)
+		return errors.Wrap(os.WriteFile(fn, b, 0644), "write "+fn)
+	})
+}
+
+// String describes the helper, without the database connection (it may contain a password).
+func (jr *javaRunner) String() string {
+	if len(jr.Config.Helper) != 0 {
+		return "helper " + strings.Join(jr.Config.Helper, " ")
+	}
+	return "java JDAPI helper in " + jr.Config.OracleHome
+}
+
+// redactArgs returns a copy of the command line, with the password of the database connection masked.
+func redactArgs(args []string) []string {
+	args = append([]string(nil), args...)
+	for i, a := range args {
+		if conn, ok := strings.CutPrefix(a, "-Dforms.db.conn="); ok {
+			args[i] = "-Dforms.db.conn=" + redactConn(conn)
+		}
+	}
+	return args
+}
+
+// redactConn masks the password of the user/password@db connection string.
+func redactConn(conn string) string {
+	user, rest, ok := strings.Cut(conn, "/")
+	if !ok {
+		return conn
+	}
+	var db string
+	if i := strings.LastIndexByte(rest, '@'); i >= 0 {
+		db = rest[i:]
+	}
+	return user + "/***" + db
+}
+
+// Ready waits till a helper is running, starting one if needed
+// (bounded by the StartupTimeout), so the conversions need not wait for it.
+func (jr *javaRunner) Ready(ctx context.Context) error {
+	if jr.err != nil {
+		return jr.err
+	}
+	return jr.pool.ready(ctx)
+}
+
+// ctxReader stops reading when ctx is done.
+type ctxReader struct {
+	ctx context.Context
+	io.Reader
+}
+
+func (r ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.Reader.Read(p)
+}
+
+// PoolStats returns the state of the helpers.
+func (jr *javaRunner) PoolStats() poolStats { return jr.pool.Stats() }
+
 /*
 export CT_JAVA_HOME="/oracle/fmw12c/product/jdk"
 export DISPLAY="aix-dev-ab7.unosoft.local:0"
@@ -133,135 +266,141 @@ export XDG_SESSION_ID="3798"
 */
 
 func (jr *javaRunner) start(ctx context.Context) (cl HTTPClient, err error) {
-	if jr.classpath == "" {
-		if jr.classes, err = os.MkdirTemp("", "forms2xml-classes-"); err != nil {
-			if err != nil {
-				return cl, errors.Wrap(err, "create temp dir for classes")
-			}
-		}
-
-		FS, err := fs.Sub(classesFS, "classes")
-		if err != nil {
-			return cl, err
-		}
-		for _, fn := range []string{"unosoft/forms/Serve$ConvertHandler.class", "unosoft/forms/Serve.class"} {
-			b, err := fs.ReadFile(FS, fn)
-			if err != nil {
-				return cl, errors.Wrap(err, "read "+fn)
-			}
-			fn = filepath.Join(jr.classes, fn)
-			os.MkdirAll(filepath.Dir(fn), 0755)
-			if err = os.WriteFile(fn, b, 0644); err != nil {
-				return cl, errors.Wrap(err, "write "+fn)
-			}
-		}
-		if jr.oracleHome == "" {
-			cmd := exec.Command("find", "/oracle", "-type", "f", "-name", "frmjdapi.jar")
-			b, _ := cmd.Output()
-			if err != nil && len(b) == 0 {
-				return cl, errors.Wrapf(err, "%v", cmd.Args)
-			}
-			jr.oracleHome = filepath.Dir(filepath.Dir(string(bytes.SplitN(b, []byte("\n"), 2)[0])))
+	// The helper listens on a Unix domain socket in a private directory, or an ephemeral port,
+	// and reports the address when it is ready (see waitReady).
+	listen, sockDir := "127.0.0.1:0", ""
+	if jr.Config.Transport != "tcp" {
+		if sockDir, err = os.MkdirTemp("", "forms2xml-jdapi-"); err != nil { // 0700
+			log.Printf("socket dir: %+v, using TCP", err)
+		} else if fn := filepath.Join(sockDir, "jdapi.sock"); len(fn) >= maxUnixPath {
+			log.Printf("socket path %q is too long, using TCP", fn)
+		} else {
+			listen = "unix:" + fn
 		}
-		// /oracle/fmw12c/product/jdk/bin/java -classpath /oracle/fmw12c/product/wlserver/../jlib/frmjdapi.jar:/oracle/fmw12c/product/wlserver/../jlib/frmxmltools.jar:/oracle/fmw12c/product/wlserver/../oracle_common/modules/oracle.xdk/xmlparserv2.jar oracle.forms.util.xmltools.XML2Forms userid=bruno_owner/xxx@ae7_dev overwrite=yes /tmp/x.xml /tmp/x.fmb
-
-		jr.classpath = jr.classes + ":" +
-			filepath.Join(jr.oracleHome, "jlib", "frmjdapi.jar") + ":" +
-			filepath.Join(jr.oracleHome, "jlib", "frmxmltools.jar")
-		for _, dn := range []string{"oracle_common/modules/oracle.xdk", "lib"} {
-			fn := filepath.Join(jr.oracleHome, dn, "xmlparserv2.jar")
-			if _, err := os.Stat(fn); err == nil {
-				jr.classpath += ":" + fn
-				break
-			}
+	}
+	removeSockDir := func() {
+		if sockDir != "" {
+			os.RemoveAll(sockDir)
 		}
 	}
-	log.Println("classpath:", jr.classpath)
-
-	netAddr, err := net.ResolveTCPAddr("tcp", "localhost:0")
+	ctx, cancel := context.WithCancel(ctx)
+	var cmd *exec.Cmd
+	if c := jr.Config; len(c.Helper) != 0 {
+		cmd = exec.CommandContext(ctx, c.Helper[0], append(c.Helper[1:len(c.Helper):len(c.Helper)], listen)...)
+	} else {
+		args := append(c.JVMOptions[:len(c.JVMOptions):len(c.JVMOptions)],
+			"-cp", jr.classpath,
+			"-Djava.library.path="+filepath.Join(c.OracleHome, "lib"),
+			"-Dforms.lib.path="+jr.FormsLibPath,
+			"-Dforms.db.conn="+jr.DbConn,
+			"unosoft.forms.Serve", listen)
+		cmd = exec.CommandContext(ctx, c.Java, args...)
+	}
+	// let the JVM shut down (see Serve's shutdown hook), but not forever
+	cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+	cmd.WaitDelay = stopTimeout
+	cmd.Env = jr.Config.environ(jr.Display, jr.FormsLibPath)
+	args := redactArgs(cmd.Args)
+	log.Println(args)
+	cl.ErrBuf = &lockedBuffer{}
+	cmd.Stderr = cl.ErrBuf
+	cmd.SysProcAttr = &syscall.SysProcAttr{Pdeathsig: syscall.SIGTERM}
+	pr, pw, err := os.Pipe()
 	if err != nil {
+		cancel()
+		removeSockDir()
 		return cl, err
 	}
-	l, err := net.ListenTCP("tcp", netAddr)
+	cmd.Stdout = pw
+	err = cmd.Start()
+	pw.Close()
 	if err != nil {
-		return cl, err
+		pr.Close()
+		cancel()
+		removeSockDir()
+		return cl, errors.Wrapf(err, "%v", args)
 	}
-	addr := l.Addr().(*net.TCPAddr).String()
-	l.Close()
-
-	env := os.Environ()
-	for i := 0; i < len(env); i++ {
-		e := env[i]
-		if i := strings.IndexByte(e, '='); i < 0 {
-			env[i] = env[0]
-			env = env[1:]
-			i--
-		} else {
-			switch e[:i] {
-			case "TERM", "DISPLAY", "PATH", "FORMS_PATH", "ORACLE_HOME", "LD_LIBRARY_PATH":
-				env[i] = env[0]
-				env = env[1:]
-				i--
-			}
-		}
+	exitErr, exited := make(chan error, 1), make(chan struct{})
+	go func() {
+		exitErr <- cmd.Wait()
+		removeSockDir()
+		close(exited)
+	}()
+	addr, err := waitReady(ctx, pr, exitErr, jr.Config.StartupTimeout.or(DefaultStartupTimeout))
+	if err != nil {
+		cancel()
+		return cl, errors.Wrapf(err, "%v: stderr:\n%s", args, cl.ErrBuf.String())
 	}
-	ctx, cancel := context.WithCancel(ctx)
-	cmd := exec.CommandContext(ctx, "java", "-cp", jr.classpath,
-		"-Djava.library.path="+filepath.Join(jr.oracleHome, "lib"),
-		"-Dforms.lib.path="+jr.FormsLibPath,
-		"-Dforms.db.conn="+jr.DbConn,
-		"unosoft.forms.Serve", addr)
-	cmd.Env = append(os.Environ(),
-		"DISPLAY="+jr.Display,
-		"TERM=xterm",
-		"FORMS_PATH="+jr.FormsLibPath,
-		"PATH="+filepath.Join(jr.oracleHome, "bin")+":/usr/lib64/qt-3.3/bin:"+os.Getenv("PATH"),
-		"ORACLE_HOME="+jr.oracleHome,
-		"LD_LIBRARY_PATH="+filepath.Join(jr.oracleHome, "bin")+":"+filepath.Join(jr.oracleHome, "lib")+":"+os.Getenv("LD_LIBRARY_PATH"),
-	)
-	log.Println(cmd.Env[len(cmd.Env)-5:])
-	log.Println(cmd.Args)
-	cl.ErrBuf = &strings.Builder{}
-	cmd.Stderr = cl.ErrBuf
-	cmd.SysProcAttr = &syscall.SysProcAttr{Pdeathsig: syscall.SIGTERM}
+	log.Printf("%v is ready on %s", args, addr)
 
 	cl.Cancel = func() {
 		log.Println("CANCEL " + addr)
 		cancel()
 	}
 	cl.Client = retryablehttp.NewClient()
-	cl.Client.RetryMax = 1
+	// do retries the transient errors, with another helper
+	cl.Client.RetryMax = 0
+	cl.Client.ErrorHandler = retryablehttp.PassthroughErrorHandler
 	cl.Client.RequestLogHook = func(logger retryablehttp.Logger, req *http.Request, nth int) {
 		if nth > 0 {
 			logger.Printf("REQUEST[%d] to %q with %q", nth, req.URL, req.Header)
 		}
 	}
-	cl.URL = "http://" + addr
+	cl.Addr, cl.URL = addr, "http://"+addr
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		// the host is ignored, every connection goes to the socket
+		cl.URL = "http://jdapi"
+		cl.Client.HTTPClient.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		}
+	}
+	cl.PID = cmd.Process.Pid
+	cl.Exited = exited
 
-	return cl, cmd.Start()
+	return cl, nil
 }
 
-func (jr *javaRunner) NewClient(ctx context.Context) HTTPClient {
-	var cl HTTPClient
+// waitReady waits for the "READY host:port" line of the helper on its stdout,
+// returning the address. The rest of the output is discarded.
+func waitReady(ctx context.Context, stdout io.ReadCloser, exited <-chan error, timeout time.Duration) (string, error) {
+	ready := make(chan string, 1)
+	go func() {
+		defer stdout.Close()
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			if addr, ok := strings.CutPrefix(scanner.Text(), "READY "); ok {
+				select {
+				case ready <- strings.TrimSpace(addr):
+				default:
+				}
+			}
+		}
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
 	select {
-	case cl = <-jr.freeClients:
-	default:
-	}
-	if cl.Client == nil {
-		cl = <-jr.newClients
+	case addr := <-ready:
+		return addr, nil
+	case err := <-exited:
+		return "", fmt.Errorf("helper exited before it was ready: %v", err)
+	case <-timer.C:
+		return "", fmt.Errorf("helper is not ready after %s", timeout)
+	case <-ctx.Done():
+		return "", ctx.Err()
 	}
-	go func() { <-ctx.Done(); cl.Cancel() }()
-	return cl
 }
 
 func (jr *javaRunner) Convert(ctx context.Context, w io.Writer, r io.Reader, mimeType string) error {
-	b, closer, err := iohlp.ReadAll(r, 1<<20)
+	b, cleanup, err := iohlp.Slurp(r, 1<<20)
 	if err != nil {
 		return errors.Wrap(err, "read all")
 	}
-	defer closer.Close()
+	defer cleanup()
 	resp, err := jr.do(ctx, func(URL string) (*retryablehttp.Request, error) {
-		req, err := retryablehttp.NewRequest("POST", URL, b)
+		req, err := retryablehttp.NewRequestWithContext(ctx, "POST", URL, b)
 		if err != nil {
 			return nil, errors.Wrap(err, URL)
 		}
@@ -287,14 +426,11 @@ func (jr *javaRunner) Convert(ctx context.Context, w io.Writer, r io.Reader, mim
 		return errors.Wrapf(err, "POST to %q with %q", URL, mimeType)
 	}
 	if resp.StatusCode >= 400 {
-		b, closer, _ := iohlp.ReadAll(resp.Body, 1<<20)
-		s := string(b)
-		closer.Close()
-		return errors.Wrap(errors.New(resp.Status), s)
+		return responseError(resp, "")
 	}
 	fn := strings.TrimPrefix(resp.Header.Get("Location"), "file://")
 	if fn == "" {
-		_, err = io.Copy(w, resp.Body)
+		_, err = io.Copy(w, ctxReader{ctx: ctx, Reader: resp.Body})
 		return errors.Wrap(err, "copying from response")
 	}
 	fh, err := os.Open(fn)
@@ -304,34 +440,64 @@ func (jr *javaRunner) Convert(ctx context.Context, w io.Writer, r io.Reader, mim
 	defer fh.Close()
 	os.Remove(fh.Name())
 	log.Printf("copying from %q...", fh.Name())
-	n, err := io.Copy(w, fh)
+	n, err := io.Copy(w, ctxReader{ctx: ctx, Reader: fh})
 	log.Printf("copied %d bytes: %v", n, err)
 	return errors.Wrap(err, "copy response")
 }
 
 func (jr *javaRunner) do(ctx context.Context, makeRequest func(address string) (*retryablehttp.Request, error)) (*http.Response, error) {
+	if jr.err != nil {
+		return nil, jr.err
+	}
 	var err error
 	var resp *http.Response
 	for i := 0; i < jr.MaxRetries; i++ {
-		cl := jr.NewClient(context.Background())
+		if i != 0 {
+			select {
+			case <-ctx.Done():
+				return nil, errors.WithMessage(ctx.Err(), err.Error())
+			case <-time.After(1 * time.Second):
+			}
+		}
+		var w *javaWorker
+		if w, err = jr.pool.get(ctx); err != nil {
+			ce := requestError(ctx, err, "")
+			if ce.Kind == ErrUnknown { // the helper could not start
+				ce.Kind = ErrCrash
+			}
+			if err = ce; ctx.Err() != nil || !ce.Kind.Retryable() {
+				return nil, err
+			}
+			log.Println(err)
+			continue
+		}
 		var req *retryablehttp.Request
-		if req, err = makeRequest(cl.URL); err != nil {
-			return nil, errors.WithMessage(err, cl.URL)
+		if req, err = makeRequest(w.URL); err != nil {
+			jr.pool.put(w, false)
+			return nil, errors.WithMessage(err, w.URL)
 		}
-		if resp, err = cl.Do(req); err == nil {
-			defer func() {
-				select {
-				case jr.freeClients <- cl:
-				default:
-					cl.Close()
-				}
-			}()
+		stderrLen := w.ErrBuf.Len()
+		if resp, err = w.Do(req); err == nil && resp.StatusCode < 500 {
+			// the worker is busy until the response is read
+			resp.Body = &poolBody{ReadCloser: resp.Body, release: func() { jr.pool.put(w, false) }}
 			return resp, nil
 		}
-		cl.Close()
-		err = errors.Wrap(err, cl.ErrBuf.String())
-		log.Println(err)
-		time.Sleep(1 * time.Second)
+		var ce *ConvertError
+		if err == nil {
+			// the conversion failed, the stack trace is on stderr
+			ce = responseError(resp, w.ErrBuf.Since(stderrLen))
+			jr.pool.put(w, ce.Kind == ErrCrash)
+		} else if ce = requestError(ctx, err, w.ErrBuf.Since(stderrLen)); ctx.Err() != nil {
+			// the helper may still be converting: stop it
+			jr.pool.abort(w)
+			return nil, ce
+		} else {
+			jr.pool.put(w, true)
+		}
+		if err = ce; !ce.Kind.Retryable() {
+			return nil, err
+		}
+		log.Printf("retry: %v", err)
 	}
 	return nil, err
 }
@@ -339,28 +505,31 @@ func (jr *javaRunner) do(ctx context.Context, makeRequest func(address string) (
 func (jr *javaRunner) ConvertFiles(ctx context.Context, dst, src string) error {
 	resp, err := jr.do(ctx, func(URL string) (*retryablehttp.Request, error) {
 		URL += "?src=" + url.QueryEscape(src) + "&dst=" + url.QueryEscape(dst)
-		return retryablehttp.NewRequest("GET", URL, nil)
+		return retryablehttp.NewRequestWithContext(ctx, "GET", URL, nil)
 	})
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 400 {
-		b, closer, _ := iohlp.ReadAll(resp.Body, 1<<20)
-		s := string(b)
-		closer.Close()
-		return errors.Wrap(errors.New(resp.Status), s)
+		return responseError(resp, "")
 	}
 	return nil
 }
 
 func (jr *javaRunner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	b, closer, err := iohlp.ReadAll(r.Body, 1<<20)
+	// The src and dst parameters make the helper read and write files on this host,
+	// which is for the local ConvertFiles only, not for the network.
+	if r.URL.RawQuery != "" {
+		http.Error(w, "ERROR: file access (src, dst) is not served, POST the module", http.StatusForbidden)
+		return
+	}
+	b, cleanup, err := iohlp.Slurp(r.Body, 1<<20)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer closer.Close()
+	defer cleanup()
 	var mimeType string
 	if r.Method == "POST" {
 		mimeType = r.Header.Get("Content-Type")
@@ -371,10 +540,11 @@ func (jr *javaRunner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
-	uri := r.URL.RequestURI()
-	resp, err := jr.do(r.Context(), func(URL string) (*retryablehttp.Request, error) {
+	uri := r.URL.EscapedPath()
+	ctx := r.Context()
+	resp, err := jr.do(ctx, func(URL string) (*retryablehttp.Request, error) {
 		URL += uri
-		req, err := retryablehttp.NewRequest(r.Method, URL, b)
+		req, err := retryablehttp.NewRequestWithContext(ctx, r.Method, URL, b)
 		if err != nil {
 			return nil, err
 		}
@@ -385,7 +555,18 @@ func (jr *javaRunner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return req, nil
 	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		// keep the protocol, so the client can classify the error, too
+		code, msg := http.StatusInternalServerError, err.Error()
+		var ce *ConvertError
+		if errors.As(err, &ce) {
+			if msg = "ERROR: " + ce.Message; ce.Message == "" {
+				msg += ce.Error()
+			}
+			if ce.Kind == ErrTimeout {
+				code = http.StatusGatewayTimeout
+			}
+		}
+		http.Error(w, msg, code)
 		return
 	}
 	defer resp.Body.Close()
@@ -399,8 +580,10 @@ func (jr *javaRunner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				if strings.HasPrefix(fh.Name(), os.TempDir()) {
 					os.Remove(fh.Name())
 				}
+				// the body is the file, not the (empty) response
+				w.Header().Del("Content-Length")
 				w.WriteHeader(resp.StatusCode)
-				io.Copy(w, fh)
+				io.Copy(w, ctxReader{ctx: ctx, Reader: fh})
 				fh.Close()
 				return
 			}
@@ -0,0 +1,214 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package jdapitest is a fake of the JDAPI helper (unosoft.forms.Serve),
+// speaking the same HTTP protocol, for testing without Oracle Forms.
+//
+// The fake .fmb is the Magic followed by the XML, so the conversions are
+// reversible, and any well-formed XML is a valid module.
+//
+//	POST / with Content-Type: application/x-oracle-forms (or anything but application/xml)
+//		converts the fmb in the body to XML: 200 with the XML in the body.
+//	POST / with Content-Type: application/xml (or Accept: application/x-oracle-forms)
+//		converts the XML in the body to a temporary fmb: 201 with its Location (file://).
+//	GET /?src=a.fmb&dst=a.xml
+//		converts the src fmb to the dst XML: 201 with Location of dst.
+//	GET /?src=a.xml&dst=a.fmb
+//		converts the src XML to the dst fmb (a temporary file if dst is empty): 201 with its Location.
+//
+// Errors are returned as 500 text/plain "ERROR: " + message.
+//
+// When listening, "READY host:port" (or "READY unix:/path") is printed on stdout.
+package jdapitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Magic is the header of the fake fmb files.
+const Magic = "FAKEFMB\n"
+
+// EncodeFMB returns the fake fmb of the Forms XML, checking that it is well-formed.
+func EncodeFMB(x []byte) ([]byte, error) {
+	dec := xml.NewDecoder(bytes.NewReader(x))
+	var seen bool
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+		if _, ok := tok.(xml.StartElement); ok {
+			seen = true
+		}
+	}
+	if !seen {
+		return nil, errors.New("invalid XML: no root element")
+	}
+	return append([]byte(Magic), x...), nil
+}
+
+// DecodeFMB returns the Forms XML of the fake fmb.
+func DecodeFMB(b []byte) ([]byte, error) {
+	if !bytes.HasPrefix(b, []byte(Magic)) {
+		return nil, errors.New("not a form module (bad magic)")
+	}
+	return b[len(Magic):], nil
+}
+
+// Handler serves the JDAPI helper protocol.
+type Handler struct {
+	// TempDir of the converted files, os.TempDir() if empty.
+	TempDir string
+	// Delay is the duration of each conversion. Like JDAPI, it is not interrupted
+	// by the client going away.
+	Delay time.Duration
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Got %s with ct=%s", r.Method, r.Header.Get("Content-Type"))
+	if h.Delay > 0 && r.Method != "HEAD" {
+		time.Sleep(h.Delay)
+	}
+	if err := h.serve(w, r); err != nil {
+		log.Printf("EXC %+v", err)
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, "ERROR: "+err.Error())
+	}
+}
+
+func (h Handler) serve(w http.ResponseWriter, r *http.Request) error {
+	q := r.URL.Query()
+	srcName, dstName := q.Get("src"), q.Get("dst")
+	var src []byte
+	var fromXML bool
+	switch r.Method {
+	case "GET":
+		fromXML = strings.HasSuffix(srcName, ".xml")
+		var err error
+		if src, err = os.ReadFile(srcName); err != nil {
+			return err
+		}
+	case "POST":
+		fromXML = r.Header.Get("Content-Type") == "application/xml" ||
+			r.Header.Get("Accept") == "application/x-oracle-forms"
+		var err error
+		if src, err = io.ReadAll(r.Body); err != nil {
+			return err
+		}
+		dstName = ""
+	default:
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, "only POST is allowed! (got %s)", r.Method)
+		return nil
+	}
+
+	if !fromXML {
+		// fmb -> XML
+		x, err := DecodeFMB(src)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		if dstName == "" {
+			w.WriteHeader(http.StatusOK)
+			_, err = w.Write(x)
+			return err
+		}
+		if err = os.WriteFile(dstName, x, 0644); err != nil {
+			return err
+		}
+		return created(w, dstName)
+	}
+
+	// XML -> fmb
+	b, err := EncodeFMB(src)
+	if err != nil {
+		return err
+	}
+	if dstName == "" {
+		fh, err := os.CreateTemp(h.TempDir, "fmb2xml-*.fmb")
+		if err != nil {
+			return err
+		}
+		dstName = fh.Name()
+		fh.Close()
+	}
+	if err = os.WriteFile(dstName, b, 0644); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/x-oracle-forms")
+	return created(w, dstName)
+}
+
+func created(w http.ResponseWriter, fn string) error {
+	fn, err := filepath.Abs(fn)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Location", "file://"+fn)
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+// ListenAndServe serves the protocol on the address, given as unosoft.forms.Serve's
+// argument ([host]:port, host defaults to 127.0.0.1, port 0 is an ephemeral port;
+// or unix:/path of a Unix domain socket), till ctx is done.
+func ListenAndServe(ctx context.Context, addr string) error {
+	return Serve(ctx, addr, Handler{})
+}
+
+// Serve is ListenAndServe with the handler.
+func Serve(ctx context.Context, addr string, h http.Handler) error {
+	network, ready := "tcp", ""
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		network, addr, ready = "unix", path, "unix:"
+	} else if strings.HasPrefix(addr, ":") {
+		addr = "127.0.0.1" + addr
+	}
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return err
+	}
+	ready += l.Addr().String()
+	log.Println("Start listening on " + ready)
+	fmt.Println("READY " + ready)
+	srv := http.Server{Handler: h}
+	go func() {
+		<-ctx.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		srv.Shutdown(ctx)
+		cancel()
+	}()
+	if err = srv.Serve(l); errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	return err
+}
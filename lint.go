@@ -0,0 +1,366 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+// The lint severities, named as the SARIF levels.
+const (
+	lintError   = "error"
+	lintWarning = "warning"
+	lintNote    = "note"
+	lintOff     = "off"
+)
+
+type lintReport func(n *transform.Node, format string, args ...any)
+
+// lintRule is a check run on each module.
+type lintRule struct {
+	ID, Description string
+	// Severity is the default severity, overridable in the config file.
+	Severity string
+	Check    func(module *transform.Node, report lintReport)
+}
+
+// lintRules is the rule registry.
+var lintRules = []lintRule{
+	{ID: "item-prompt", Severity: lintWarning,
+		Description: "visible input items should have a Prompt or a Hint",
+		Check: func(module *transform.Node, report lintReport) {
+			for _, it := range module.Find("Item") {
+				if !isInputItem(it) || it.Get("Visible") == "false" || it.Get("CanvasName") == "" {
+					continue
+				}
+				if it.Get("Prompt") == "" && it.Get("Hint") == "" {
+					report(it, "%s has neither Prompt nor Hint", it.Get("ItemType"))
+				}
+			}
+		},
+	},
+	{ID: "hardcoded-color", Severity: lintWarning,
+		Description: "colors should come from VisualAttributes",
+		Check: func(module *transform.Node, report lintReport) {
+			module.Walk(func(n *transform.Node) error {
+				switch n.Name {
+				case "VisualAttribute", "PropertyClass":
+					return transform.SkipChildren
+				}
+				if n.Get("ParentModule") != "" || n.Get("VisualAttributeName") != "" {
+					return nil // the colors come from the subclass or the VisualAttribute
+				}
+				var colors []string
+				for _, a := range n.Attr {
+					if strings.HasSuffix(a.Name.Local, "Color") {
+						colors = append(colors, a.Name.Local+"="+a.Value)
+					}
+				}
+				if len(colors) != 0 {
+					report(n, "hardcoded %s", strings.Join(colors, ", "))
+				}
+				return nil
+			})
+		},
+	},
+	{ID: "item-font", Severity: lintWarning,
+		Description: "fonts should come from VisualAttributes, not set on the items",
+		Check: func(module *transform.Node, report lintReport) {
+			keys := append(append([]string(nil), transform.RemoveVAs...), transform.RemovePromptVAs...)
+			for _, it := range module.Find("Item") {
+				var fonts []string
+				for _, k := range keys {
+					if it.Has(k) {
+						fonts = append(fonts, k)
+					}
+				}
+				if len(fonts) != 0 {
+					report(it, "font set directly: %s", strings.Join(fonts, ", "))
+				}
+			}
+		},
+	},
+	{ID: "block-order-by", Severity: lintNote,
+		Description: "base table blocks should have an ORDER BY clause",
+		Check: func(module *transform.Node, report lintReport) {
+			for _, b := range module.Find("Block") {
+				if b.Get("QueryDataSourceName") == "" || (b.Get("QueryDataSourceType") != "" && b.Get("QueryDataSourceType") != "Table") {
+					continue
+				}
+				if strings.TrimSpace(transform.Text(b.Get("OrderByClause"))) == "" {
+					report(b, "no ORDER BY for %s", b.Get("QueryDataSourceName"))
+				}
+			}
+		},
+	},
+	{ID: "zero-width", Severity: lintError,
+		Description: "navigable items must have a positive width",
+		Check: func(module *transform.Node, report lintReport) {
+			for _, it := range module.Find("Item") {
+				if !isInputItem(it) || it.Get("KeyboardNavigable") == "false" || it.Get("Enabled") == "false" ||
+					it.Get("Visible") == "false" || !it.Has("Width") {
+					continue
+				}
+				if atoi(it.Get("Width")) <= 0 {
+					report(it, "navigable item with Width=%q", it.Get("Width"))
+				}
+			}
+		},
+	},
+	{ID: "empty-trigger", Severity: lintWarning,
+		Description: "triggers should have code",
+		Check: func(module *transform.Node, report lintReport) {
+			for _, t := range module.Find("Trigger") {
+				if t.Get("ParentModule") != "" {
+					continue // the code is inherited
+				}
+				if strings.TrimSpace(transform.Text(t.Get("TriggerText"))) == "" {
+					report(t, "empty trigger text")
+				}
+			}
+		},
+	},
+	{ID: "window-subclass", Severity: lintWarning,
+		Description: "windows should be subclassed from the object library",
+		Check: func(module *transform.Node, report lintReport) {
+			for _, w := range module.Find("Window") {
+				if w.Get("ParentModule") == "" {
+					report(w, "window is not subclassed")
+				}
+			}
+		},
+	},
+}
+
+func isInputItem(it *transform.Node) bool {
+	switch it.Get("ItemType") {
+	case "", "Text Item", "List Item", "Check Box", "Radio Group":
+		return true
+	}
+	return false
+}
+
+// lintConfig is the config file: the severity (error, warning, note or off) of the rules by ID.
+type lintConfig struct {
+	Rules map[string]string `json:"rules"`
+}
+
+// readLintConfig reads the JSON config file, returning the rules with their configured severity.
+func readLintConfig(fn string) ([]lintRule, error) {
+	rules := append([]lintRule(nil), lintRules...)
+	if fn == "" {
+		return rules, nil
+	}
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	var cfg lintConfig
+	if err = json.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %q: %w", fn, err)
+	}
+	for id, sev := range cfg.Rules {
+		switch sev {
+		case lintError, lintWarning, lintNote, lintOff:
+		default:
+			return nil, fmt.Errorf("%q: rule %s: unknown severity %q", fn, id, sev)
+		}
+		var found bool
+		for i := range rules {
+			if found = rules[i].ID == id; found {
+				rules[i].Severity = sev
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%q: unknown rule %q", fn, id)
+		}
+	}
+	return rules, nil
+}
+
+type lintResult struct {
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Path     string `json:"path"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func lintModule(fn string, module *transform.Node, rules []lintRule) []lintResult {
+	var results []lintResult
+	// the lines of the XML converted from an .fmb do not point into the file
+	fmb := isFmb(fn)
+	for _, r := range rules {
+		if r.Severity == lintOff {
+			continue
+		}
+		r.Check(module, func(n *transform.Node, format string, args ...any) {
+			line := n.Line
+			if fmb {
+				line = 0
+			}
+			results = append(results, lintResult{File: fn, Line: line, Path: n.Path(),
+				Rule: r.ID, Severity: r.Severity, Message: fmt.Sprintf(format, args...)})
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Line < results[j].Line })
+	return results
+}
+
+// lintFiles lints each form, printing the results to w in the given format (text, json or sarif).
+// Returns an error if there are error level results.
+func lintFiles(ctx context.Context, w io.Writer, converter Converter, rules []lintRule, format string, files []string) error {
+	var results []lintResult
+	for _, fn := range files {
+		root, err := readForm(ctx, converter, fn)
+		if err != nil {
+			return err
+		}
+		results = append(results, lintModule(fn, root.Module(), rules)...)
+	}
+	var err error
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if results == nil {
+			results = []lintResult{}
+		}
+		err = enc.Encode(results)
+	case "sarif":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(newSarifLog(rules, results))
+	default:
+		for _, r := range results {
+			pos := r.File
+			if r.Line > 0 {
+				pos += ":" + strconv.Itoa(r.Line)
+			}
+			if _, err = fmt.Fprintf(w, "%s: %s: %s: %s [%s]\n", pos, r.Severity, r.Path, r.Message, r.Rule); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	var n int
+	for _, r := range results {
+		if r.Severity == lintError {
+			n++
+		}
+	}
+	if n != 0 {
+		return fmt.Errorf("found %d errors", n)
+	}
+	return nil
+}
+
+// SARIF 2.1.0 (https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html), only the used parts.
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri,omitempty"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+		DefaultConfig    struct {
+			Level string `json:"level"`
+		} `json:"defaultConfiguration"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+	sarifLocation struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+			Region *sarifRegion `json:"region,omitempty"`
+		} `json:"physicalLocation"`
+		LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+	}
+	sarifRegion struct {
+		StartLine int `json:"startLine"`
+	}
+	sarifLogicalLocation struct {
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+	}
+)
+
+func newSarifLog(rules []lintRule, results []lintResult) sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{Name: "forms2xml", InformationURI: "https://github.com/UNO-SOFT/forms2xml"}},
+		// results must not be null
+		Results: make([]sarifResult, 0, len(results)),
+	}
+	for _, r := range rules {
+		if r.Severity == lintOff {
+			continue
+		}
+		sr := sarifRule{ID: r.ID, ShortDescription: sarifMessage{Text: r.Description}}
+		sr.DefaultConfig.Level = r.Severity
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sr)
+	}
+	for _, r := range results {
+		var loc sarifLocation
+		loc.PhysicalLocation.ArtifactLocation.URI = filepath.ToSlash(r.File)
+		if r.Line > 0 {
+			loc.PhysicalLocation.Region = &sarifRegion{StartLine: r.Line}
+		}
+		loc.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: r.Path}}
+		run.Results = append(run.Results, sarifResult{RuleID: r.Rule, Level: r.Severity,
+			Message: sarifMessage{Text: r.Message}, Locations: []sarifLocation{loc}})
+	}
+	return sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+}
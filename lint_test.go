@@ -0,0 +1,185 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/UNO-SOFT/forms2xml/jdapitest"
+	"github.com/UNO-SOFT/forms2xml/transform"
+	"github.com/google/go-cmp/cmp"
+)
+
+const lintXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Module version="101020002" xmlns="http://xmlns.oracle.com/Forms">
+  <FormModule Name="EMP">
+%s
+  </FormModule>
+</Module>`
+
+func TestLintRules(t *testing.T) {
+	for _, tc := range []struct {
+		Rule, Module string
+		Want         []string
+	}{
+		{Rule: "item-prompt", Module: `<Block Name="B">
+  <Item Name="A" ItemType="Text Item" CanvasName="C"/>
+  <Item Name="B" CanvasName="C" Hint="b"/>
+  <Item Name="C" ItemType="Check Box" CanvasName="C" Prompt="c"/>
+  <Item Name="D" ItemType="Text Item"/>
+  <Item Name="E" ItemType="Text Item" CanvasName="C" Visible="false"/>
+  <Item Name="F" ItemType="Push Button" CanvasName="C"/>
+</Block>`,
+			Want: []string{"FormModule[EMP]/Block[B]/Item[A]: Text Item has neither Prompt nor Hint"}},
+		{Rule: "hardcoded-color", Module: `<Block Name="B">
+  <Item Name="A" ForegroundColor="red" BackgroundColor="white" Width="1"/>
+  <Item Name="S" ForegroundColor="red" ParentModule="G_LIB" ParentName="S"/>
+  <Item Name="V" ForegroundColor="red" VisualAttributeName="NORMAL_ITEM"/>
+</Block>
+<VisualAttribute Name="VA" ForegroundColor="red"/>
+<PropertyClass Name="PC"><Item Name="X" ForegroundColor="red"/></PropertyClass>`,
+			Want: []string{"FormModule[EMP]/Block[B]/Item[A]: hardcoded ForegroundColor=red, BackgroundColor=white"}},
+		{Rule: "item-font", Module: `<Block Name="B">
+  <Item Name="A" FontName="Arial" FontSize="900" PromptFontName="Arial"/>
+  <Item Name="B" VisualAttributeName="VA"/>
+</Block>`,
+			Want: []string{"FormModule[EMP]/Block[B]/Item[A]: font set directly: FontName, FontSize, PromptFontName"}},
+		{Rule: "block-order-by", Module: `<Block Name="A" QueryDataSourceName="EMP"/>
+<Block Name="B" QueryDataSourceName="EMP" OrderByClause="ename"/>
+<Block Name="C" QueryDataSourceName="EMP" OrderByClause="  "/>
+<Block Name="D" QueryDataSourceName="p" QueryDataSourceType="Procedure"/>
+<Block Name="E"/>`,
+			Want: []string{"FormModule[EMP]/Block[A]: no ORDER BY for EMP", "FormModule[EMP]/Block[C]: no ORDER BY for EMP"}},
+		{Rule: "zero-width", Module: `<Block Name="B">
+  <Item Name="A" ItemType="Text Item" Width="0"/>
+  <Item Name="B" ItemType="Text Item" Width="10"/>
+  <Item Name="C" ItemType="Text Item" Width="0" KeyboardNavigable="false"/>
+  <Item Name="D" ItemType="Text Item" Width="0" Enabled="false"/>
+  <Item Name="E" ItemType="Display Item" Width="0"/>
+  <Item Name="F" ItemType="Text Item"/>
+</Block>`,
+			Want: []string{`FormModule[EMP]/Block[B]/Item[A]: navigable item with Width="0"`}},
+		{Rule: "empty-trigger", Module: `<Trigger Name="WHEN-NEW-FORM-INSTANCE" TriggerText="&amp;#10; "/>
+<Trigger Name="PRE-FORM" TriggerText="null;"/>
+<Trigger Name="KEY-EXIT" ParentModule="G_LIB"/>`,
+			Want: []string{"FormModule[EMP]/Trigger[WHEN-NEW-FORM-INSTANCE]: empty trigger text"}},
+		{Rule: "window-subclass", Module: `<Window Name="W1"/>
+<Window Name="W2" ParentModule="G_OLB" ParentName="W_STD"/>`,
+			Want: []string{"FormModule[EMP]/Window[W1]: window is not subclassed"}},
+	} {
+		t.Run(tc.Rule, func(t *testing.T) {
+			root, err := transform.ParseTree(strings.NewReader(fmt.Sprintf(lintXML, tc.Module)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var rules []lintRule
+			for _, r := range lintRules {
+				if r.ID == tc.Rule {
+					rules = append(rules, r)
+				}
+			}
+			if len(rules) != 1 {
+				t.Fatalf("no rule %s", tc.Rule)
+			}
+			var got []string
+			for _, r := range lintModule("emp.xml", root.Module(), rules) {
+				if r.Line <= 0 {
+					t.Errorf("no line: %+v", r)
+				}
+				got = append(got, r.Path+": "+r.Message)
+			}
+			if d := cmp.Diff(tc.Want, got); d != "" {
+				t.Error(d)
+			}
+		})
+	}
+}
+
+func TestLintFiles(t *testing.T) {
+	dir := t.TempDir()
+	x := fmt.Sprintf(lintXML, `<Block Name="B"><Item Name="A" ItemType="Text Item" Width="0"/></Block>
+<Window Name="W"/>`)
+	files := writeTestForms(t, dir, map[string]string{"emp": x})
+	b, err := jdapitest.EncodeFMB([]byte(x))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmb := filepath.Join(dir, "emp.fmb")
+	if err = os.WriteFile(fmb, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	files = append(files, fmb)
+
+	cfg := filepath.Join(dir, "lint.json")
+	if err = os.WriteFile(cfg, []byte(`{"rules": {"window-subclass": "error", "block-order-by": "off"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := readLintConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	var buf bytes.Buffer
+	if err = lintFiles(ctx, &buf, fakeConverter{}, rules, "text", files); err == nil || err.Error() != "found 4 errors" {
+		t.Errorf("got %v", err)
+	}
+	// the .fmb has no lines
+	want := []string{
+		files[0] + ":4: error: FormModule[EMP]/Block[B]/Item[A]: navigable item with Width=\"0\" [zero-width]",
+		files[0] + ":5: error: FormModule[EMP]/Window[W]: window is not subclassed [window-subclass]",
+		fmb + ": error: FormModule[EMP]/Block[B]/Item[A]: navigable item with Width=\"0\" [zero-width]",
+		fmb + ": error: FormModule[EMP]/Window[W]: window is not subclassed [window-subclass]",
+	}
+	if d := cmp.Diff(want, strings.Split(strings.TrimSpace(buf.String()), "\n")); d != "" {
+		t.Error(d)
+	}
+
+	buf.Reset()
+	if err = lintFiles(ctx, &buf, fakeConverter{}, rules, "sarif", files); err == nil {
+		t.Error("no error")
+	}
+	var log sarifLog
+	if err = json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(lintRules)-1 || len(run.Results) != 4 {
+		t.Fatalf("got %+v", run)
+	}
+	for i, r := range run.Results {
+		loc := r.Locations[0].PhysicalLocation
+		if (i < 2) != (loc.Region != nil) {
+			t.Errorf("%d. %s: region %+v", i, loc.ArtifactLocation.URI, loc.Region)
+		}
+	}
+
+	for _, s := range []string{`{"rules": {"nope": "error"}}`, `{"rules": {"zero-width": "fatal"}}`, `[`} {
+		if err = os.WriteFile(cfg, []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = readLintConfig(cfg); err == nil {
+			t.Errorf("%s: no error", s)
+		}
+	}
+}
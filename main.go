@@ -19,6 +19,7 @@ import (
 	"bytes"
 	"context"
 	_ "embed"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -40,7 +41,8 @@ import (
 
 func main() {
 	if err := Main(); err != nil {
-		log.Fatalf("%+v", err)
+		log.Printf("%+v", err)
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -69,22 +71,29 @@ func Main() error {
 		return src, dst, nil
 	}
 
-	var converter Converter
-	cmdXML := ff.Command{Name: "xml", ShortHelp: "convert to-from XML",
+	// converter reads the source modules, dstConverter writes the targets of 6to11.
+	var converter, dstConverter Converter
+	FS := ff.NewFlagSet("xml")
+	xmlTimeout := FS.Duration(0, "timeout", 10*time.Second, "timeout of the conversion (after the helper has started)")
+	cmdXML := ff.Command{Name: "xml", Flags: FS, ShortHelp: "convert to-from XML",
 		Usage: "xml <source file> [destination file]",
 		Exec: func(ctx context.Context, args []string) error {
 			xmlSrc, xmlDst, err := srcDst(args)
 			if err != nil {
 				return err
 			}
-			ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			ctx, cancel, err := withTimeout(ctx, *xmlTimeout, converter)
+			if err != nil {
+				return err
+			}
 			err = convertFiles(ctx, converter, xmlDst, xmlSrc)
 			cancel()
 			return err
 		},
 	}
 
-	var jr *javaRunner
+	// The local helpers (nil if remote): jrDst is started only if the target differs.
+	var jr, jrDst *javaRunner
 	cmdServe := ff.Command{Name: "serve", ShortHelp: "serve (start java only)",
 		Usage: "serve <address to listen on>",
 		Exec: func(ctx context.Context, args []string) error {
@@ -92,7 +101,11 @@ func Main() error {
 				return fmt.Errorf("address is required")
 			}
 			cmdServeAddress := args[0]
+			if jr == nil {
+				return fmt.Errorf("serve needs a local JDAPI helper, not %q", jdapiURLs[0])
+			}
 			http.Handle("/", jr)
+			http.Handle("/debug/pool", jr.pool)
 			log.Println("Listening on " + cmdServeAddress)
 			server := http.Server{Addr: cmdServeAddress}
 			go func() {
@@ -116,9 +129,11 @@ func Main() error {
 		},
 	}
 
-	FS := ff.NewFlagSet("6to11")
+	FS = ff.NewFlagSet("6to11")
 	upNoTransform := FS.Bool('n', "no-transform", "don't transform")
 	upSuffix := FS.String('S', "suffix", "-v11", "suffix of converted files")
+	upNoValidate := FS.Bool(0, "no-validate", "don't validate the transformed XML before converting it back")
+	upTimeout := FS.Duration(0, "timeout", 20*time.Second, "timeout of the conversion (after the helpers have started)")
 	cmd6211 := ff.Command{Name: "6to11", Flags: FS,
 		ShortHelp: "convert from Forms v6 to v11",
 		Exec: func(ctx context.Context, args []string) error {
@@ -126,8 +141,11 @@ func Main() error {
 			if err != nil {
 				return err
 			}
-			ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
-			err = convertFiles6to11(ctx, converter, upDst, upSrc, !*upNoTransform, *upSuffix)
+			ctx, cancel, err := withTimeout(ctx, *upTimeout, converter, dstConverter)
+			if err != nil {
+				return err
+			}
+			err = convertFiles6to11(ctx, converter, dstConverter, upDst, upSrc, !*upNoTransform, !*upNoValidate, *upSuffix)
 			cancel()
 			return err
 		},
@@ -137,8 +155,10 @@ func Main() error {
 	FS = ff.NewFlagSet("watch")
 	watchFileSuffix := FS.String('S', "suffix", "-v11", "suffix of converted files")
 	watchNoTransform := FS.BoolDefault('n', "no-transform", false, "don't transform")
+	watchNoValidate := FS.Bool(0, "no-validate", "don't validate the transformed XML before converting it back")
 	FS.IntVar(&concurrency, 0, "concurrency", concurrency, "maximum number of conversions running in parallel")
 	watchServeAddress := FS.String(0, "http", "", "HTTP address to listen on")
+	watchTimeout := FS.Duration(0, "timeout", 20*time.Second, "timeout of a file's conversion (after the helpers have started)")
 	cmdWatch := ff.Command{Name: "watch", Flags: FS,
 		ShortHelp: "watch a directory and transform all appearing files",
 		Exec: func(ctx context.Context, args []string) error {
@@ -147,7 +167,10 @@ func Main() error {
 			if err != nil {
 				return err
 			}
-			http.Handle("/", jr)
+			if jr != nil {
+				http.Handle("/", jr)
+				http.Handle("/debug/pool", jr.pool)
+			}
 			grp, ctx := errgroup.WithContext(ctx)
 			if *watchServeAddress != "" {
 				grp.Go(func() error {
@@ -156,21 +179,340 @@ func Main() error {
 				})
 			}
 			grp.Go(func() error {
-				return watchConvert(ctx, converter, watchDst, watchSrc, !*watchNoTransform, *watchFileSuffix, concurrency)
+				return watchConvert(ctx, converter, dstConverter, watchDst, watchSrc, !*watchNoTransform, !*watchNoValidate, *watchFileSuffix, concurrency, *watchTimeout)
 			})
 			return grp.Wait()
 		},
 	}
 
+	FS = ff.NewFlagSet("query")
+	queryFormat := FS.StringEnum('f', "format", "output format", "table", "json")
+	queryAttrs := FS.StringList('a', "attr", "attribute to print (* for all)")
+	cmdQuery := ff.Command{Name: "query", Flags: FS,
+		ShortHelp: "query the Forms object tree",
+		Usage:     "query [flags] <query> <.fmb or .xml file>...",
+		LongHelp: `The query is a list of steps separated by / (child) or // (descendant),
+each an element name or *, with optional [predicates]:
+
+	[@Attr]  [!@Attr]  [@Attr=value]  [@Attr!=value]  [@Attr~regexp]  [@Attr!~regexp]
+
+For example
+
+	query '//Item[@ItemType="Check Box"][!@Prompt]' *.fmb
+	query -a TriggerText '//Trigger[@Name=WHEN-VALIDATE-ITEM][@TriggerText~"(?i)go_block"]' *.xml`,
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) < 2 {
+				return fmt.Errorf("query and at least one source file is required")
+			}
+			q, err := transform.ParseQuery(args[0])
+			if err != nil {
+				return err
+			}
+			return queryFiles(ctx, os.Stdout, converter, q, *queryFormat, *queryAttrs, args[1:])
+		},
+	}
+
+	FS = ff.NewFlagSet("apply")
+	applyDryRun := FS.Bool('n', "dry-run", "only show the changes")
+	applySuffix := FS.String('S', "suffix", "", "suffix of the patched files (overwrite if empty)")
+	cmdApply := ff.Command{Name: "apply", Flags: FS,
+		ShortHelp: "apply a patch document to many forms",
+		Usage:     "apply [flags] <patch.json> <.fmb or .xml file>...",
+		LongHelp: `The patch is a JSON list of operations, each with a "select" query (see query)
+and any of "set", "delete", "insert" (with "position"), "remove" and "replace":
+
+	[{"select": "//Item[@VisualAttributeName=OLD_VA]", "set": {"Bevel": "Lowered"}},
+	 {"select": "//Block", "insert": "<Trigger Name=\"KEY-EXIT\" TriggerText=\"exit_form;\"/>"},
+	 {"select": "//Trigger", "replace": {"attr": "TriggerText", "regexp": "(?i)go_item", "with": "GO_ITEM"}}]`,
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) < 2 {
+				return fmt.Errorf("patch and at least one source file is required")
+			}
+			fh, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			patch, err := transform.ReadPatch(fh)
+			fh.Close()
+			if err != nil {
+				return fmt.Errorf("%s: %w", args[0], err)
+			}
+			return applyPatch(ctx, os.Stdout, converter, patch, args[1:], *applySuffix, *applyDryRun)
+		},
+	}
+
+	FS = ff.NewFlagSet("render")
+	renderDst := FS.String('o', "output", "render", "output directory")
+	renderTransform := FS.Bool('t', "transform", "transform before rendering")
+	cmdRender := ff.Command{Name: "render", Flags: FS,
+		ShortHelp: "render canvases to SVG with an HTML index per form",
+		Usage:     "render [flags] <.fmb or .xml file>...",
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("source file is required")
+			}
+			return renderFiles(ctx, converter, *renderDst, *renderTransform, args)
+		},
+	}
+
+	FS = ff.NewFlagSet("doc")
+	docDst := FS.String('o', "output", "doc", "output directory")
+	cmdDoc := ff.Command{Name: "doc", Flags: FS,
+		ShortHelp: "generate HTML documentation of the forms",
+		Usage:     "doc [flags] <.fmb or .xml file>...",
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("source file is required")
+			}
+			return docFiles(ctx, converter, *docDst, args)
+		},
+	}
+
+	FS = ff.NewFlagSet("export")
+	exportDst := FS.String('o', "output", "export", "output directory")
+	exportTypes := FS.String(0, "types", "", "JSON file mapping Forms data types to JSON Schema types")
+	cmdExport := ff.Command{Name: "export", Flags: FS,
+		ShortHelp: "export the forms as a JSON model plus JSON Schema",
+		Usage:     "export [flags] <.fmb or .xml file>...",
+		LongHelp: `For each form, <form>.json is the model of blocks, items, LOVs and layout,
+<form>.schema.json is the JSON Schema of the form's data.
+
+The --types file overrides the data type mapping, for example
+
+	{"Date": {"type": "string", "format": "date"}, "Number": {"type": "string", "pattern": "^-?[0-9.]+$"}}`,
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("source file is required")
+			}
+			types, err := readSchemaTypes(*exportTypes)
+			if err != nil {
+				return err
+			}
+			return exportFiles(ctx, converter, *exportDst, types, args)
+		},
+	}
+
+	FS = ff.NewFlagSet("codegen")
+	codegenDst := FS.String('o', "output", "codegen", "output directory")
+	codegenPkg := FS.String('p', "package", "forms", "Go package name")
+	codegenTmpls := FS.StringList('T', "template", "template file overriding the go, sql, select, insert or update templates")
+	cmdCodegen := ff.Command{Name: "codegen", Flags: FS,
+		ShortHelp: "generate Go structs and SQL from the base-table blocks",
+		Usage:     "codegen [flags] <.fmb or .xml file>...",
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("source file is required")
+			}
+			return codegenFiles(ctx, converter, *codegenDst, *codegenPkg, *codegenTmpls, args)
+		},
+	}
+
+	FS = ff.NewFlagSet("checkdb")
+	checkdbSchema := FS.String('s', "schema", "", "ALL_TAB_COLUMNS export (.json or .csv)")
+	cmdCheckDB := ff.Command{Name: "checkdb", Flags: FS,
+		ShortHelp: "check block data sources and LOV queries against an offline schema dump",
+		Usage:     "checkdb -s <all_tab_columns.csv> <.fmb or .xml file>...",
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("source file is required")
+			}
+			if *checkdbSchema == "" {
+				return fmt.Errorf("schema is required")
+			}
+			schema, err := readDBSchema(*checkdbSchema)
+			if err != nil {
+				return err
+			}
+			return checkDBFiles(ctx, os.Stdout, converter, schema, args)
+		},
+	}
+
+	FS = ff.NewFlagSet("validate")
+	validateTransform := FS.Bool('t', "transform", "validate the transformed form")
+	cmdValidate := ff.Command{Name: "validate", Flags: FS,
+		ShortHelp: "validate the Forms XML against the (derived) forms.xsd",
+		Usage:     "validate [-t] <.fmb or .xml file>...",
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("source file is required")
+			}
+			return validateFiles(ctx, os.Stdout, converter, *validateTransform, args)
+		},
+	}
+
+	FS = ff.NewFlagSet("lint")
+	lintFormat := FS.StringEnum('f', "format", "output format", "text", "json", "sarif")
+	lintConfigFile := FS.String('c', "config", "", `JSON config file: {"rules": {"<rule>": "error|warning|note|off"}}`)
+	var lintHelp strings.Builder
+	lintHelp.WriteString("Rules (with default severity):\n")
+	for _, r := range lintRules {
+		fmt.Fprintf(&lintHelp, "\n\t%-16s %-8s %s", r.ID, r.Severity, r.Description)
+	}
+	cmdLint := ff.Command{Name: "lint", Flags: FS,
+		ShortHelp: "check the forms against the coding rules",
+		Usage:     "lint [-c config.json] [-f text|json|sarif] <.fmb or .xml file>...",
+		LongHelp:  lintHelp.String(),
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("source file is required")
+			}
+			rules, err := readLintConfig(*lintConfigFile)
+			if err != nil {
+				return err
+			}
+			return lintFiles(ctx, os.Stdout, converter, rules, *lintFormat, args)
+		},
+	}
+
+	FS = ff.NewFlagSet("extract")
+	extractOut := FS.String('o', "output", "", "translation file (.po or .xlf), merged if exists; stdout if empty")
+	extractFormat := FS.String('f', "format", "", "output format: po or xliff (default: by the extension of the output)")
+	extractSourceLang := FS.String(0, "source-lang", "hu", "language of the forms")
+	extractTargetLang := FS.String(0, "target-lang", "", "language of the translation")
+	cmdI18nExtract := ff.Command{Name: "extract", Flags: FS,
+		ShortHelp: "extract the translatable strings into a PO or XLIFF file",
+		Usage:     "i18n extract [-o messages.po] <.fmb or .xml file>...",
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("source file is required")
+			}
+			format := *extractFormat
+			if format == "" && isXLIFF(*extractOut) {
+				format = "xliff"
+			}
+			return i18nExtract(ctx, os.Stderr, converter, *extractOut, format, *extractSourceLang, *extractTargetLang, args)
+		},
+	}
+	FS = ff.NewFlagSet("apply")
+	i18nApplyFile := FS.String('t', "translation", "", "translation file (.po or .xlf)")
+	i18nApplySuffix := FS.String('S', "suffix", "", "suffix of the localized files (default: -<target language>)")
+	i18nApplyForce := FS.Bool(0, "force", "write the forms with untranslated or stale strings, too")
+	cmdI18nApply := ff.Command{Name: "apply", Flags: FS,
+		ShortHelp: "produce the localized forms from a translation file",
+		Usage:     "i18n apply -t messages.po <.fmb or .xml file>...",
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("source file is required")
+			}
+			if *i18nApplyFile == "" {
+				return fmt.Errorf("translation file is required")
+			}
+			return i18nApply(ctx, os.Stderr, converter, *i18nApplyFile, *i18nApplySuffix, *i18nApplyForce, args)
+		},
+	}
+	cmdI18n := ff.Command{Name: "i18n",
+		ShortHelp: "extract and apply translations",
+		LongHelp: `Prompts, Hints, Labels, Titles, Alert messages, list elements, boilerplate text
+and MESSAGE('...') literals of triggers and program units are keyed by their object path.`,
+		Subcommands: []*ff.Command{&cmdI18nExtract, &cmdI18nApply},
+	}
+
+	FS = ff.NewFlagSet("audit")
+	auditFormat := FS.StringEnum('f', "format", "output format", "table", "csv", "json")
+	cmdAudit := ff.Command{Name: "audit", Flags: FS,
+		ShortHelp: "list the dynamic SQL, HOST and file access in triggers and program units",
+		Usage:     "audit [-f table|csv|json] <.fmb or .xml file>...",
+		LongHelp: `Flags FORMS_DDL, EXEC_SQL, EXECUTE IMMEDIATE, DBMS_SQL, HOST, TEXT_IO.FOPEN
+and string-concatenated SET_BLOCK_PROPERTY(..., DEFAULT_WHERE/ONETIME_WHERE, ...) calls.`,
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("source file is required")
+			}
+			return auditFiles(ctx, os.Stdout, converter, *auditFormat, args)
+		},
+	}
+
+	FS = ff.NewFlagSet("estimate")
+	estimateFormat := FS.StringEnum('f', "format", "output format", "csv", "html")
+	estimateOut := FS.String('o', "output", "", "output file (stdout if empty)")
+	estimateWeightsFile := FS.String('w', "weights", "", "JSON weights (hours per occurrence)")
+	cmdEstimate := ff.Command{Name: "estimate", Flags: FS,
+		ShortHelp: "estimate the 6to11 migration effort of the forms",
+		Usage:     "estimate [-w weights.json] [-f csv|html] [-o output] <.fmb or .xml file>...",
+		LongHelp: `The score of a form is the weighted sum of the stacked canvases collapsed,
+the item types replaced, the obsolete built-in calls, the PL/SQL lines,
+the VisualAttribute overrides removed and the objects injected by the
+transformation. Weights are read from a JSON file, e.g.
+
+	{"form": 2, "stackedCanvases": 4, "badItemTypes": 0.5, "obsoleteBuiltins": 1,
+	 "plsqlLines": 0.01, "vaOverrides": 0.02, "injected": 0.1}`,
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("source file is required")
+			}
+			weights, err := readEstimateWeights(*estimateWeightsFile)
+			if err != nil {
+				return err
+			}
+			if *estimateOut == "" || *estimateOut == "-" {
+				return estimateFiles(ctx, os.Stdout, converter, weights, *estimateFormat, args)
+			}
+			return writeFile(*estimateOut, func(w io.Writer) error {
+				return estimateFiles(ctx, w, converter, weights, *estimateFormat, args)
+			})
+		},
+	}
+
+	cmdCheckJava := ff.Command{Name: "checkjava",
+		ShortHelp: "print the settings of the Java JDAPI helper, and check its jars",
+		LongHelp: `The settings are the built-in profile (11g or 12c), overridden by the
+defaults then the profile of the config file, then the flags. The config file is
+
+	{"java": "...", "oracleHome": "...", "jars": ["jlib/frmjdapi.jar", ...],
+	 "classpath": [...], "jvmOptions": ["-Xmx2g"], "env": {"NLS_LANG": "..."},
+	 "transport": "unix", "minWorkers": 1, "maxWorkers": 4, "maxJobs": 100, "idleTimeout": "5m", "pingInterval": "30s",
+	 "profiles": {"12c": {...}}}
+
+The state of the running helpers is served on /debug/pool by serve and watch --http.`,
+		Exec: func(ctx context.Context, args []string) error {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			var errs []error
+			for i, r := range []*javaRunner{jr, jrDst} {
+				if r == nil {
+					if isRemote(jdapiURLs[i]) {
+						fmt.Printf("%s: remote %s\n", []string{"source", "target"}[i], jdapiURLs[i])
+					}
+					continue
+				}
+				if err := enc.Encode(r.Config); err != nil {
+					return err
+				}
+				errs = append(errs, r.err)
+			}
+			return errors.Join(errs...)
+		},
+	}
+
 	FS = ff.NewFlagSet("forms2xml")
-	FS.StringVar(&jdapiURLs[0], 0, "jdapi-src", jdapiURLs[0], "SRC Form JDAPI helper HTTP listener URL")
-	FS.StringVar(&jdapiURLs[1], 0, "jdapi-dst", jdapiURLs[1], "DEST Form JDAPI helper HTTP listener URL")
+	FS.StringVar(&jdapiURLs[0], 0, "jdapi-src", jdapiURLs[0], "database connection of the source Forms' JDAPI helper, or comma separated URLs of remote forms2xml serve instances (URL#weight=N)")
+	FS.StringVar(&jdapiURLs[1], 0, "jdapi-dst", jdapiURLs[1], "database connection of the target Forms' JDAPI helper (6to11), or comma separated URLs of remote forms2xml serve instances (URL#weight=N)")
+	remoteHealthInterval := FS.Duration(0, "remote-health-interval", DefaultHealthInterval, "health check period of the remote forms2xml serve instances")
 	FS.StringVar(&formsLibPath, 0, "forms.lib.path", formsLibPath, "FORMS_PATH")
 	FS.StringVar(&display, 0, "display", os.Getenv("DISPLAY"), "DISPLAY")
+	jdapiHelper := FS.String(0, "jdapi-helper", os.Getenv("FORMS2XML_JDAPI_HELPER"), "command to start instead of the Java JDAPI helper (e.g. fakejdapi), the address is appended")
+	javaConfigFile := FS.String(0, "java-config", os.Getenv("FORMS2XML_JAVA_CONFIG"), "JSON config file of the Java JDAPI helper")
+	javaProfile := FS.String(0, "java-profile", os.Getenv("FORMS2XML_JAVA_PROFILE"), "profile of the Java JDAPI helper (11g, 12c or from the config file)")
+	javaDstProfile := FS.String(0, "java-dst-profile", os.Getenv("FORMS2XML_JAVA_DST_PROFILE"), "profile of the target Java JDAPI helper of 6to11 (default: --java-profile)")
+	javaBin := FS.String(0, "java", "", "java binary")
+	oracleHome := FS.String(0, "oracle-home", "", "ORACLE_HOME of the Forms installation")
+	javaJars := FS.StringList(0, "jar", "JDAPI jar, relative to ORACLE_HOME (repeatable, replaces the defaults)")
+	javaClasspath := FS.StringList(0, "classpath", "extra classpath entry (repeatable)")
+	jvmOptions := FS.StringList(0, "jvm-opt", "JVM option, e.g. -Xmx2g (repeatable)")
+	javaEnv := FS.StringList(0, "java-env", "KEY=VALUE environment override of the helper (repeatable)")
+	javaStartupTimeout := FS.Duration(0, "java-startup-timeout", 0, "maximum wait for the helper to become ready (default 2m)")
+	javaTransport := FS.String(0, "java-transport", "", "transport to the helper: unix (a Unix domain socket, TCP if the JVM cannot listen on it) or tcp (default unix)")
+	var javaPoolCfg javaConfig
+	FS.IntVar(&javaPoolCfg.MinWorkers, 0, "java-min-workers", 0, "number of helpers kept running even when idle")
+	FS.IntVar(&javaPoolCfg.MaxWorkers, 0, "java-max-workers", 0, "maximum number of helpers (default: concurrency)")
+	FS.IntVar(&javaPoolCfg.MaxJobs, 0, "java-max-jobs", 0, "conversions before a helper is restarted, -1 for unlimited (default 100)")
+	javaIdleTimeout := FS.Duration(0, "java-idle-timeout", 0, "stop the helpers idle for this long (default 5m)")
+	javaPingInterval := FS.Duration(0, "java-ping-interval", 0, "health check period of the idle helpers (default 30s)")
 	app := ff.Command{Name: "forms2xml", Flags: FS,
 		ShortHelp:   "Oracle Forms .fmb <-> .xml with optional conversion",
+		LongHelp:    exitCodesHelp(),
 		Exec:        cmdXML.Exec,
-		Subcommands: []*ff.Command{&cmdXML, &cmdServe, &cmdTransform, &cmd6211, &cmdWatch},
+		Subcommands: []*ff.Command{&cmdXML, &cmdServe, &cmdTransform, &cmd6211, &cmdWatch, &cmdQuery, &cmdApply, &cmdRender, &cmdDoc, &cmdExport, &cmdCodegen, &cmdCheckDB, &cmdValidate, &cmdLint, &cmdI18n, &cmdAudit, &cmdEstimate, &cmdCheckJava},
 	}
 
 	if err := app.Parse(os.Args[1:]); err != nil {
@@ -184,21 +526,69 @@ func Main() error {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
-	jr = newJavaRunner(ctx, jdapiURLs[0], formsLibPath, display, 0, concurrency)
-	jr.MaxRetries = 2
-	converter = Converter(jr)
-	log.Println("converter:", converter)
+	javaCfg, err := readJavaConfig(*javaConfigFile, *javaProfile)
+	if err != nil {
+		return err
+	}
+	env, err := parseEnv(*javaEnv)
+	if err != nil {
+		return fmt.Errorf("java-env: %w", err)
+	}
+	javaFlags := javaConfig{
+		Helper: strings.Fields(*jdapiHelper), Java: *javaBin, OracleHome: *oracleHome,
+		Jars: *javaJars, Classpath: *javaClasspath, JVMOptions: *jvmOptions, Env: env,
+		StartupTimeout: duration(*javaStartupTimeout), Transport: *javaTransport,
+		MinWorkers: javaPoolCfg.MinWorkers, MaxWorkers: javaPoolCfg.MaxWorkers, MaxJobs: javaPoolCfg.MaxJobs,
+		IdleTimeout: duration(*javaIdleTimeout), PingInterval: duration(*javaPingInterval),
+	}
+	javaCfg = javaCfg.override(javaFlags)
+	if isRemote(jdapiURLs[0]) {
+		if converter, err = newRemoteConverter(ctx, jdapiURLs[0], *remoteHealthInterval); err != nil {
+			return fmt.Errorf("jdapi-src: %w", err)
+		}
+	} else {
+		jr = newJavaRunner(ctx, jdapiURLs[0], formsLibPath, display, javaCfg, 0, concurrency)
+		jr.MaxRetries = 2
+		converter = jr
+	}
+	switch {
+	case isRemote(jdapiURLs[1]):
+		if dstConverter, err = newRemoteConverter(ctx, jdapiURLs[1], *remoteHealthInterval); err != nil {
+			return fmt.Errorf("jdapi-dst: %w", err)
+		}
+	case jdapiURLs[1] == jdapiURLs[0] && (*javaDstProfile == "" || *javaDstProfile == *javaProfile):
+		dstConverter = converter
+	default:
+		dstCfg := javaCfg
+		if *javaDstProfile != "" {
+			if dstCfg, err = readJavaConfig(*javaConfigFile, *javaDstProfile); err != nil {
+				return fmt.Errorf("java-dst-profile: %w", err)
+			}
+			dstCfg = dstCfg.override(javaFlags)
+		}
+		jrDst = newJavaRunner(ctx, jdapiURLs[1], formsLibPath, display, dstCfg, 0, concurrency)
+		jrDst.MaxRetries = 2
+		dstConverter = jrDst
+	}
+	log.Println("converter:", converter, "target:", dstConverter)
 
 	return app.Run(ctx)
 }
 
-func watchConvert(ctx context.Context, converter Converter, dstDir, srcDir string, doTransform bool, suffix string, concurrency int) error {
+func watchConvert(ctx context.Context, srcConverter, dstConverter Converter, dstDir, srcDir string, doTransform, validate bool, suffix string, concurrency int, timeout time.Duration) error {
 	tokens := make(chan struct{}, concurrency)
 	eventCh := make(chan notify.EventInfo, 16)
 	if err := notify.Watch(srcDir, eventCh, eventsToWatch...); err != nil {
 		return fmt.Errorf("watch: %w", err)
 	}
-	for evt := range eventCh {
+	defer notify.Stop(eventCh)
+	for {
+		var evt notify.EventInfo
+		select {
+		case <-ctx.Done():
+			return nil
+		case evt = <-eventCh:
+		}
 		fn := evt.Path()
 		bn := filepath.Base(fn)
 		if !strings.HasSuffix(bn, ".fmb") {
@@ -209,19 +599,30 @@ func watchConvert(ctx context.Context, converter Converter, dstDir, srcDir strin
 			tokens <- struct{}{}
 			defer func() { <-tokens }()
 			for i := 0; i < 10; i++ {
-				err := convertFiles6to11(
-					ctx, converter,
-					filepath.Join(dstDir, bn), fn, doTransform, suffix,
-				)
+				fileCtx, cancel, err := withTimeout(ctx, timeout, srcConverter, dstConverter)
 				if err == nil {
+					err = convertFiles6to11(
+						fileCtx, srcConverter, dstConverter,
+						filepath.Join(dstDir, bn), fn, doTransform, validate, suffix,
+					)
+					cancel()
+				}
+				// only the transient errors may succeed next time
+				if err == nil || ctx.Err() != nil || !isRetryable(err) {
+					if err != nil {
+						log.Printf("%s: %v", fn, err)
+					}
 					break
 				}
 				log.Println(err)
-				time.Sleep(time.Duration(i) * time.Second)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Duration(i) * time.Second):
+				}
 			}
 		}()
 	}
-	return nil
 }
 
 func transformFiles(dst, src string) error {
@@ -250,7 +651,9 @@ func transformFiles(dst, src string) error {
 	return out.Close()
 }
 
-func convertFiles6to11(ctx context.Context, converter Converter, dst, src string, doTransform bool, suffix string) error {
+// convertFiles6to11 reads the src module to XML with srcConverter, transforms it,
+// and writes it with dstConverter into dst.
+func convertFiles6to11(ctx context.Context, srcConverter, dstConverter Converter, dst, src string, doTransform, validate bool, suffix string) error {
 	if dst == "" {
 		dst = strings.TrimSuffix(src, ".fmb") + suffix + ".fmb"
 	}
@@ -310,7 +713,18 @@ func convertFiles6to11(ctx context.Context, converter Converter, dst, src string
 			xmlSource = tr
 			grp.Go(func() error {
 				log.Println("start transform")
-				err := P.ProcessStream(xmlW, xmlR)
+				var err error
+				if !validate {
+					err = P.ProcessStream(xmlW, xmlR)
+				} else {
+					// Buffer the transformed XML, to fail before the converter gets any of it.
+					var buf bytes.Buffer
+					if err = P.ProcessStream(&buf, xmlR); err == nil {
+						if err = validateXML(bytes.NewReader(buf.Bytes())); err == nil {
+							_, err = buf.WriteTo(xmlW)
+						}
+					}
+				}
 				log.Printf("xml->xml: %+v", err)
 				tw.CloseWithError(err)
 				if err != nil {
@@ -320,7 +734,7 @@ func convertFiles6to11(ctx context.Context, converter Converter, dst, src string
 			})
 		}
 		log.Println("start convert")
-		err := converter.Convert(ctx, out, xmlSource, "application/xml")
+		err := dstConverter.Convert(ctx, out, xmlSource, "application/xml")
 		log.Printf("xml->fmb: %+v", err)
 		xr.CloseWithError(err)
 		if err != nil {
@@ -328,7 +742,7 @@ func convertFiles6to11(ctx context.Context, converter Converter, dst, src string
 		}
 		return nil
 	})
-	err = converter.Convert(ctx, xw, inp, "application/x-oracle-forms")
+	err = srcConverter.Convert(ctx, xw, inp, "application/x-oracle-forms")
 	log.Printf("fmb->xml: %+v", err)
 	xw.CloseWithError(err)
 	if err != nil {
@@ -340,6 +754,24 @@ func convertFiles6to11(ctx context.Context, converter Converter, dst, src string
 	return out.Close()
 }
 
+// withTimeout waits for the converters to be ready (starting the local helpers,
+// bounded by their startup timeout), then returns ctx with the timeout of the conversion.
+func withTimeout(ctx context.Context, timeout time.Duration, converters ...Converter) (context.Context, context.CancelFunc, error) {
+	for _, c := range converters {
+		if r, ok := c.(interface{ Ready(context.Context) error }); ok {
+			if err := r.Ready(ctx); err != nil {
+				return ctx, func() {}, err
+			}
+		}
+	}
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
+}
+
 func convertFiles(ctx context.Context, converter Converter, dst, src string) error {
 	mimeType := "application/x-oracle-forms"
 	inp := io.ReadCloser(os.Stdin)
@@ -0,0 +1,342 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/UNO-SOFT/forms2xml/jdapitest"
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+// The test binary is the JDAPI helper, too: started with this environment
+// variable set, it serves the fake protocol (see jdapitest) on the address in its last argument.
+// The fakeDelayEnv is the duration of each conversion.
+const (
+	fakeJDAPIEnv = "FORMS2XML_TEST_FAKE_JDAPI"
+	fakeDelayEnv = "FORMS2XML_TEST_FAKE_DELAY"
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeJDAPIEnv) != "" {
+		var h jdapitest.Handler
+		if s := os.Getenv(fakeDelayEnv); s != "" {
+			var err error
+			if h.Delay, err = time.ParseDuration(s); err != nil {
+				log.Fatalf("%s: %+v", fakeDelayEnv, err)
+			}
+		}
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		err := jdapitest.Serve(ctx, os.Args[len(os.Args)-1], h)
+		cancel()
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		os.Exit(0)
+	}
+	os.Setenv(fakeJDAPIEnv, "1")
+	os.Exit(m.Run())
+}
+
+func newTestRunner(t *testing.T) (context.Context, *javaRunner) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	t.Cleanup(cancel)
+	return ctx, newJavaRunner(ctx, "", "", "", javaConfig{Helper: []string{os.Args[0]}}, 1, 2)
+}
+
+// testModule returns a random module's XML, and writes it as a fake fmb into dir.
+func testModule(t *testing.T, dir, name string) (fn string, x []byte) {
+	var buf bytes.Buffer
+	if _, err := transform.Generate(rand.New(rand.NewPCG(1, 2)), transform.DefaultGenerateOptions).WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	x = buf.Bytes()
+	b, err := jdapitest.EncodeFMB(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn = filepath.Join(dir, name+".fmb")
+	if err = os.WriteFile(fn, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fn, x
+}
+
+func readFMB(t *testing.T, fn string) []byte {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := jdapitest.DecodeFMB(b)
+	if err != nil {
+		t.Fatalf("%s: %+v", fn, err)
+	}
+	return x
+}
+
+func TestXML(t *testing.T) {
+	ctx, jr := newTestRunner(t)
+	dir := t.TempDir()
+	src, want := testModule(t, dir, "a")
+
+	xmlFn := filepath.Join(dir, "a.xml")
+	if err := convertFiles(ctx, jr, xmlFn, src); err != nil {
+		t.Fatalf("fmb->xml: %+v", err)
+	}
+	if got, err := os.ReadFile(xmlFn); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(got, want) {
+		t.Errorf("fmb->xml: got\n%s\nwanted\n%s", got, want)
+	}
+
+	fmbFn := filepath.Join(dir, "b.fmb")
+	if err := convertFiles(ctx, jr, fmbFn, xmlFn); err != nil {
+		t.Fatalf("xml->fmb: %+v", err)
+	}
+	if got := readFMB(t, fmbFn); !bytes.Equal(got, want) {
+		t.Errorf("xml->fmb: got\n%s\nwanted\n%s", got, want)
+	}
+}
+
+func Test6to11(t *testing.T) {
+	ctx, jr := newTestRunner(t)
+	dir := t.TempDir()
+	src, want := testModule(t, dir, "a")
+
+	t.Run("no-transform", func(t *testing.T) {
+		dst := filepath.Join(dir, "a-copy.fmb")
+		if err := convertFiles6to11(ctx, jr, jr, dst, src, false, false, "-v11"); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if got := readFMB(t, dst); !bytes.Equal(got, want) {
+			t.Errorf("got\n%s\nwanted\n%s", got, want)
+		}
+	})
+
+	t.Run("transform", func(t *testing.T) {
+		if err := convertFiles6to11(ctx, jr, jr, "", src, true, true, "-v11"); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		root, err := transform.ParseTree(bytes.NewReader(readFMB(t, filepath.Join(dir, "a-v11.fmb"))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if errs := transform.Validate(root); len(errs) != 0 {
+			t.Error(errs)
+		}
+		if root.Module().Get("ConsoleWindow") != "W_MAIN" {
+			t.Errorf("not transformed: ConsoleWindow=%q", root.Module().Get("ConsoleWindow"))
+		}
+		for _, fn := range []string{"a.xml", "a-v11.xml"} {
+			if _, err := os.Stat(filepath.Join(dir, fn)); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+}
+
+// Test6to11Dual converts with a local source and a remote target helper.
+func Test6to11Dual(t *testing.T) {
+	ctx, src := newTestRunner(t)
+	_, dst := newTestRunner(t)
+	var posts []string
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		posts = append(posts, r.Header.Get("Content-Type"))
+		mu.Unlock()
+		dst.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	fn, want := testModule(t, dir, "a")
+	dstFn := filepath.Join(dir, "a-v11.fmb")
+	if err := convertFiles6to11(ctx, src, mustRemote(t, ctx, srv.URL), dstFn, fn, false, false, "-v11"); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if got := readFMB(t, dstFn); !bytes.Equal(got, want) {
+		t.Errorf("got\n%s\nwanted\n%s", got, want)
+	}
+	// the target only writes
+	if len(posts) != 1 || posts[0] != "application/xml" {
+		t.Errorf("target got %q", posts)
+	}
+	if st := src.PoolStats(); st.Started != 1 {
+		t.Errorf("source: %+v", st)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	ctx, jr := newTestRunner(t)
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() { done <- watchConvert(ctx, jr, jr, dstDir, srcDir, true, true, "-v11", 2, 0) }()
+	time.Sleep(100 * time.Millisecond)
+
+	testModule(t, srcDir, "w")
+	dst := filepath.Join(dstDir, "w.fmb")
+	for {
+		if b, err := os.ReadFile(dst); err == nil && bytes.HasPrefix(b, []byte(jdapitest.Magic)) &&
+			bytes.HasSuffix(bytes.TrimSpace(b), []byte("</Module>")) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("%s has not appeared: %v", dst, ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("watchConvert: %+v", err)
+	}
+}
+
+func TestServe(t *testing.T) {
+	_, jr := newTestRunner(t)
+	srv := httptest.NewServer(jr)
+	defer srv.Close()
+	src, want := testModule(t, t.TempDir(), "a")
+	fmb, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(body []byte, mimeType string) (*http.Response, []byte) {
+		t.Helper()
+		resp, err := http.Post(srv.URL, mimeType, bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, b
+	}
+
+	if resp, got := post(fmb, "application/x-oracle-forms"); resp.StatusCode != 200 {
+		t.Errorf("fmb->xml: %s: %s", resp.Status, got)
+	} else if !bytes.Equal(got, want) {
+		t.Errorf("fmb->xml: got\n%s\nwanted\n%s", got, want)
+	}
+
+	if resp, got := post(want, "application/xml"); resp.StatusCode != 201 {
+		t.Errorf("xml->fmb: %s: %s", resp.Status, got)
+	} else if !bytes.Equal(got, fmb) {
+		t.Errorf("xml->fmb: got\n%s\nwanted\n%s", got, fmb)
+	}
+
+	if resp, got := post([]byte("<Module><FormModule>"), "application/xml"); resp.StatusCode != 500 {
+		t.Errorf("invalid XML: %s: %s", resp.Status, got)
+	}
+}
+
+func TestStartupHandshake(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for name, tc := range map[string]struct {
+		script, want string
+	}{
+		"timeout": {"echo connecting >&2; sleep 10", "not ready after"},
+		"exit":    {"echo ORA-12154 >&2; exit 3", "exited before it was ready"},
+		"ready":   {"echo READY 127.0.0.1:1; sleep 10", ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// the address is appended, becoming $0
+			jr := &javaRunner{Config: javaConfig{Helper: []string{"sh", "-c", tc.script},
+				StartupTimeout: duration(500 * time.Millisecond)}}
+			cl, err := jr.start(ctx)
+			if tc.want == "" {
+				if err != nil {
+					t.Fatalf("%+v", err)
+				}
+				cl.Close()
+				if cl.URL != "http://127.0.0.1:1" {
+					t.Errorf("got %q", cl.URL)
+				}
+				return
+			}
+			if err == nil {
+				cl.Close()
+				t.Fatal("no error")
+			}
+			t.Log(err)
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got %q, wanted %q", err, tc.want)
+			}
+			if want := strings.Fields(tc.script)[1]; !strings.Contains(err.Error(), want) {
+				t.Errorf("stderr (%q) is not in %q", want, err)
+			}
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	for _, transport := range []string{"unix", "tcp"} {
+		t.Run(transport, func(t *testing.T) {
+			ctx, jr := newTestPool(t, javaConfig{Transport: transport})
+			dir := t.TempDir()
+			src, want := testModule(t, dir, "a")
+			xmlFn := filepath.Join(dir, "a.xml")
+			if err := convertFiles(ctx, jr, xmlFn, src); err != nil {
+				t.Fatalf("%+v", err)
+			}
+			if got, err := os.ReadFile(xmlFn); err != nil {
+				t.Fatal(err)
+			} else if !bytes.Equal(got, want) {
+				t.Errorf("got\n%s\nwanted\n%s", got, want)
+			}
+
+			st := jr.PoolStats()
+			if len(st.Workers) != 1 {
+				t.Fatalf("got %+v", st)
+			}
+			addr := st.Workers[0].Addr
+			path, isUnix := strings.CutPrefix(addr, "unix:")
+			if isUnix != (transport == "unix") {
+				t.Fatalf("%s: got %q", transport, addr)
+			}
+			if !isUnix {
+				return
+			}
+			fi, err := os.Stat(filepath.Dir(path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if perm := fi.Mode().Perm(); perm != 0700 {
+				t.Errorf("%s: got %v, wanted 0700", filepath.Dir(path), perm)
+			}
+		})
+	}
+}
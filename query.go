@@ -0,0 +1,100 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+type queryResult struct {
+	File    string            `json:"file"`
+	Path    string            `json:"path"`
+	Element string            `json:"element"`
+	Line    int               `json:"line,omitempty"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+}
+
+// queryFiles runs the query on each file, and prints the results as a table or JSON.
+func queryFiles(ctx context.Context, w io.Writer, converter Converter, q *transform.Query, format string, attrs []string, files []string) error {
+	var results []queryResult
+	for _, fn := range files {
+		root, err := readForm(ctx, converter, fn)
+		if err != nil {
+			return err
+		}
+		for _, n := range q.Select(root) {
+			res := queryResult{File: fn, Path: n.Path(), Element: n.Name, Line: n.Line}
+			if len(attrs) != 0 {
+				res.Attrs = make(map[string]string, len(attrs))
+				for _, a := range attrs {
+					if a == "*" {
+						for _, a := range n.Attr {
+							res.Attrs[a.Name.Local] = a.Value
+						}
+					} else if n.Has(a) {
+						res.Attrs[a] = n.Get(a)
+					}
+				}
+			}
+			results = append(results, res)
+		}
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if results == nil {
+			results = []queryResult{}
+		}
+		return enc.Encode(results)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	head := []string{"FILE", "LINE", "PATH"}
+	for _, a := range attrs {
+		if a != "*" {
+			head = append(head, a)
+		}
+	}
+	fmt.Fprintln(tw, strings.Join(head, "\t"))
+	for _, res := range results {
+		row := []string{res.File, strconv.Itoa(res.Line), res.Path}
+		for _, a := range attrs {
+			if a != "*" {
+				row = append(row, quoteCell(res.Attrs[a]))
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// quoteCell shortens the value to fit into one table cell.
+func quoteCell(s string) string {
+	s = strings.NewReplacer("\n", " ", "\t", " ").Replace(transform.Text(s))
+	if r := []rune(s); len(r) > 60 {
+		s = string(r[:57]) + "..."
+	}
+	return s
+}
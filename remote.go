@@ -0,0 +1,279 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tgulacsi/go/iohlp"
+)
+
+// DefaultHealthInterval is the period of the health checks of the remote converters.
+const DefaultHealthInterval = 10 * time.Second
+
+// remoteConverter converts with remote "forms2xml serve" instances, which return
+// the converted file in the body.
+//
+// The request goes to the healthy endpoint with the least outstanding requests
+// relative to its weight. On a connection error, the endpoint is marked down,
+// and the request fails over to the next one; any other error (e.g. a failed
+// conversion) is returned as is. The endpoints are health checked periodically,
+// on /debug/pool.
+type remoteConverter struct {
+	Client    *http.Client
+	endpoints []*remoteEndpoint
+
+	mu sync.Mutex
+	// next rotates the start of the search, to spread the ties.
+	next int
+}
+
+type remoteEndpoint struct {
+	URL    string
+	Weight int
+
+	// guarded by remoteConverter.mu
+	outstanding int
+	down        bool
+}
+
+// parseEndpoints parses the comma separated list of URLs, each optionally with a #weight=N fragment.
+func parseEndpoints(spec string) ([]*remoteEndpoint, error) {
+	var endpoints []*remoteEndpoint
+	for _, s := range strings.Split(spec, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+			return nil, fmt.Errorf("%q: http(s)://host[:port] is needed", s)
+		}
+		e := remoteEndpoint{Weight: 1}
+		if u.Fragment != "" {
+			v, ok := strings.CutPrefix(u.Fragment, "weight=")
+			if e.Weight, err = strconv.Atoi(v); !ok || err != nil || e.Weight <= 0 {
+				return nil, fmt.Errorf("%q: #weight=N (N > 0) is needed", s)
+			}
+			u.Fragment = ""
+		}
+		e.URL = strings.TrimSuffix(u.String(), "/")
+		endpoints = append(endpoints, &e)
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("%q: no endpoints", spec)
+	}
+	return endpoints, nil
+}
+
+// newRemoteConverter returns a converter for the endpoints, health checking them every interval till ctx is done.
+func newRemoteConverter(ctx context.Context, spec string, interval time.Duration) (*remoteConverter, error) {
+	endpoints, err := parseEndpoints(spec)
+	if err != nil {
+		return nil, err
+	}
+	rc := remoteConverter{Client: &http.Client{}, endpoints: endpoints}
+	if interval > 0 {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					rc.checkHealth(ctx)
+				}
+			}
+		}()
+	}
+	return &rc, nil
+}
+
+func (rc *remoteConverter) String() string {
+	ss := make([]string, len(rc.endpoints))
+	for i, e := range rc.endpoints {
+		ss[i] = e.URL
+		if e.Weight != 1 {
+			ss[i] += "#weight=" + strconv.Itoa(e.Weight)
+		}
+	}
+	return strings.Join(ss, ",")
+}
+
+// pick the endpoint with the least outstanding requests per weight, not tried yet.
+// The down ones are picked only if no other is left.
+func (rc *remoteConverter) pick(tried map[*remoteEndpoint]bool) *remoteEndpoint {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	var best *remoteEndpoint
+	n := len(rc.endpoints)
+	for pass := 0; pass < 2 && best == nil; pass++ {
+		for i := range n {
+			e := rc.endpoints[(rc.next+i)%n]
+			if tried[e] || pass == 0 && e.down {
+				continue
+			}
+			// (e.outstanding+1)/e.Weight < (best.outstanding+1)/best.Weight
+			if best == nil || (e.outstanding+1)*best.Weight < (best.outstanding+1)*e.Weight {
+				best = e
+			}
+		}
+	}
+	if best != nil {
+		best.outstanding++
+		rc.next = (rc.next + 1) % n
+	}
+	return best
+}
+
+func (rc *remoteConverter) done(e *remoteEndpoint, connErr bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	e.outstanding--
+	if connErr && !e.down {
+		log.Printf("remote %s is down", e.URL)
+		e.down = true
+	}
+}
+
+func (rc *remoteConverter) Convert(ctx context.Context, w io.Writer, r io.Reader, mimeType string) error {
+	b, cleanup, err := iohlp.Slurp(r, 1<<20)
+	if err != nil {
+		return errors.Wrap(err, "read all")
+	}
+	defer cleanup()
+	tried := make(map[*remoteEndpoint]bool, len(rc.endpoints))
+	for {
+		e := rc.pick(tried)
+		if e == nil {
+			return err
+		}
+		tried[e] = true
+		var connErr bool
+		connErr, err = rc.convert(ctx, e, w, b, mimeType)
+		rc.done(e, connErr)
+		if !connErr {
+			return err
+		}
+		log.Printf("%+v, failing over", err)
+	}
+}
+
+// convert with the endpoint, reporting whether the error is a connection error,
+// so nothing has been written to w yet.
+func (rc *remoteConverter) convert(ctx context.Context, e *remoteEndpoint, w io.Writer, b []byte, mimeType string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", e.URL, bytes.NewReader(b))
+	if err != nil {
+		return false, errors.Wrap(err, e.URL)
+	}
+	req.Header.Set("Content-Type", mimeType)
+	resp, err := rc.Client.Do(req)
+	if err != nil {
+		return isConnError(ctx, err), errors.Wrapf(err, "POST to %q with %q", e.URL, mimeType)
+	}
+	defer resp.Body.Close()
+	log.Printf("POST[%s] %d bytes to %q: %s", mimeType, len(b), e.URL, resp.Status)
+	if resp.StatusCode >= 400 {
+		return false, responseError(resp, "")
+	}
+	_, err = io.Copy(w, resp.Body)
+	return false, errors.Wrap(err, "copying from response")
+}
+
+// isConnError reports whether the request failed to connect, or the connection was
+// broken before the response, not because ctx is done.
+func isConnError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// checkHealth marks the endpoints up or down.
+func (rc *remoteConverter) checkHealth(ctx context.Context) {
+	for _, e := range rc.endpoints {
+		err := rc.ping(ctx, e)
+		rc.mu.Lock()
+		if down := err != nil; down != e.down {
+			log.Printf("remote %s: down=%t (%v)", e.URL, down, err)
+			e.down = down
+		}
+		rc.mu.Unlock()
+	}
+}
+
+func (rc *remoteConverter) ping(ctx context.Context, e *remoteEndpoint) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", e.URL+"/debug/pool", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := rc.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
+
+// ConvertFiles converts the local src file into dst, through Convert.
+func (rc *remoteConverter) ConvertFiles(ctx context.Context, dst, src string) error {
+	if dst == "" {
+		return fmt.Errorf("%s: destination is required for a remote converter", src)
+	}
+	mimeType := "application/x-oracle-forms"
+	if strings.HasSuffix(src, ".xml") {
+		mimeType = "application/xml"
+	}
+	inp, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer inp.Close()
+	// a failed conversion must not leave a partial dst behind
+	return writeFileAtomic(dst, func(w io.Writer) error {
+		return rc.Convert(ctx, w, inp, mimeType)
+	})
+}
+
+// isRemote reports whether the --jdapi-src/--jdapi-dst value is a list of remote converter URLs,
+// not the database connection of a local helper.
+func isRemote(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
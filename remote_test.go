@@ -0,0 +1,181 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func mustRemote(t *testing.T, ctx context.Context, spec string) *remoteConverter {
+	t.Helper()
+	rc, err := newRemoteConverter(ctx, spec, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rc
+}
+
+// countingServer serves with h, counting the conversions.
+func countingServer(t *testing.T, h http.Handler) (*httptest.Server, *atomic.Int32) {
+	var n atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pool", func(w http.ResponseWriter, r *http.Request) {})
+	mux.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n.Add(1)
+		h.ServeHTTP(w, r)
+	}))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &n
+}
+
+func TestParseEndpoints(t *testing.T) {
+	endpoints, err := parseEndpoints("http://a:1/, https://b:2#weight=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) != 2 || endpoints[0].URL != "http://a:1" || endpoints[0].Weight != 1 ||
+		endpoints[1].URL != "https://b:2" || endpoints[1].Weight != 3 {
+		t.Errorf("got %+v %+v", endpoints[0], endpoints[1])
+	}
+	for _, s := range []string{"", "a/b@c", "http://a#weight=0", "http://a#w=1", "ftp://a"} {
+		if _, err := parseEndpoints(s); err == nil {
+			t.Errorf("%q: no error", s)
+		}
+	}
+}
+
+func TestRemotePick(t *testing.T) {
+	rc := mustRemote(t, context.Background(), "http://a,http://b#weight=3,http://c")
+	a, b, c := rc.endpoints[0], rc.endpoints[1], rc.endpoints[2]
+	c.down = true
+	counts := make(map[*remoteEndpoint]int)
+	for range 8 {
+		counts[rc.pick(nil)]++
+	}
+	if counts[a] != 2 || counts[b] != 6 || counts[c] != 0 {
+		t.Errorf("weights 1:3 (and down): got a=%d b=%d c=%d", counts[a], counts[b], counts[c])
+	}
+	// the down one is the last resort
+	if e := rc.pick(map[*remoteEndpoint]bool{a: true, b: true}); e != c {
+		t.Errorf("got %+v", e)
+	}
+}
+
+func TestRemoteFailover(t *testing.T) {
+	ctx, jr := newTestRunner(t)
+	good, goodN := countingServer(t, jr)
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+	failing, failingN := countingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "ERROR: oracle.forms.jdapi.JdapiException", http.StatusInternalServerError)
+	}))
+	src, want := testModule(t, t.TempDir(), "a")
+	fmb, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rc := mustRemote(t, ctx, dead.URL+","+good.URL)
+	var buf bytes.Buffer
+	if err := rc.Convert(ctx, &buf, bytes.NewReader(fmb), "application/x-oracle-forms"); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("got\n%s\nwanted\n%s", buf.Bytes(), want)
+	}
+	if !rc.endpoints[0].down || rc.endpoints[1].down {
+		t.Errorf("dead is not down: %+v %+v", rc.endpoints[0], rc.endpoints[1])
+	}
+	if goodN.Load() != 1 {
+		t.Errorf("good got %d requests", goodN.Load())
+	}
+
+	// the conversion errors do not fail over
+	rc = mustRemote(t, ctx, failing.URL+","+good.URL)
+	rc.endpoints[1].outstanding = 10 // so failing is picked
+	if err := rc.Convert(ctx, &buf, bytes.NewReader(fmb), "application/x-oracle-forms"); err == nil {
+		t.Fatal("no error")
+	}
+	if failingN.Load() != 1 || goodN.Load() != 1 {
+		t.Errorf("failing got %d, good got %d requests", failingN.Load(), goodN.Load())
+	}
+
+	// the health check brings it back
+	rc = mustRemote(t, ctx, good.URL+","+dead.URL)
+	rc.endpoints[0].down = true
+	rc.checkHealth(ctx)
+	if rc.endpoints[0].down || !rc.endpoints[1].down {
+		t.Errorf("got %+v %+v", rc.endpoints[0], rc.endpoints[1])
+	}
+}
+
+func TestRemoteConvertFiles(t *testing.T) {
+	ctx, _ := newTestRunner(t)
+	failing, _ := countingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<Module>")) // a partial answer
+		panic(http.ErrAbortHandler)
+	}))
+	src, _ := testModule(t, t.TempDir(), "a")
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "a.xml")
+	if err := mustRemote(t, ctx, failing.URL).ConvertFiles(ctx, dst, src); err == nil {
+		t.Fatal("no error")
+	}
+	if des, err := os.ReadDir(dir); err != nil || len(des) != 0 {
+		t.Errorf("dst is left behind: %v %v", des, err)
+	}
+}
+
+// TestServeFileAccess checks that serve does not let the clients read or write the files of the host.
+func TestServeFileAccess(t *testing.T) {
+	_, jr := newTestRunner(t)
+	srv := httptest.NewServer(jr)
+	defer srv.Close()
+	dir := t.TempDir()
+	src, _ := testModule(t, dir, "a")
+	fmb, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(dir, "b.xml")
+	q := "?" + url.Values{"src": {src}, "dst": {dst}}.Encode()
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", srv.URL+q, nil),
+		httptest.NewRequest("POST", srv.URL+"?dst="+url.QueryEscape(dst), bytes.NewReader(fmb)),
+	} {
+		req.RequestURI = ""
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("%s %s: got %s", req.Method, req.URL, resp.Status)
+		}
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Errorf("%s is written: %v", dst, err)
+	}
+}
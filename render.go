@@ -0,0 +1,260 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+// renderFiles draws each canvas (and tab page) of the forms into dstDir/<form>/<canvas>.svg,
+// with an index.html per form.
+func renderFiles(ctx context.Context, converter Converter, dstDir string, doTransform bool, files []string) error {
+	for _, fn := range files {
+		root, err := readForm(ctx, converter, fn)
+		if err != nil {
+			return err
+		}
+		if doTransform {
+			var P transform.FormsXMLProcessor
+			if root, err = P.ProcessTree(root); err != nil {
+				return fmt.Errorf("transform %q: %w", fn, err)
+			}
+		}
+		name := root.Module().Get("Name")
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(fn), filepath.Ext(fn))
+		}
+		dir := filepath.Join(dstDir, fileName(name))
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		log.Printf("Render %q into %q.", fn, dir)
+		if err = renderForm(dir, fn, root); err != nil {
+			return fmt.Errorf("render %q: %w", fn, err)
+		}
+	}
+	return nil
+}
+
+func renderForm(dir, fn string, root *transform.Node) error {
+	module := root.Module()
+	sx, sy := transform.PixelScale(module)
+	r := canvasRenderer{sx: sx, sy: sy, items: make(map[string][]*transform.Node)}
+	for _, it := range module.Find("Item") {
+		if it.Get("Visible") == "false" {
+			continue
+		}
+		k := it.Get("CanvasName") + "/" + it.Get("TabPageName")
+		r.items[k] = append(r.items[k], it)
+	}
+
+	type page struct{ Title, File string }
+	var pages []page
+	for _, cv := range module.Find("Canvas") {
+		tabs := cv.Find("TabPage")
+		if len(tabs) == 0 {
+			tabs = []*transform.Node{nil}
+		}
+		for _, tp := range tabs {
+			title, svgName := cv.Get("Name"), cv.Get("Name")
+			if tp != nil {
+				title += " / " + tp.Get("Name")
+				svgName += "-" + tp.Get("Name")
+			}
+			if t := cv.Get("CanvasType"); t != "" {
+				title += " (" + t + ")"
+			}
+			svgName = fileName(svgName) + ".svg"
+			if err := writeFile(filepath.Join(dir, svgName), func(w io.Writer) error {
+				return r.render(w, cv, tp)
+			}); err != nil {
+				return err
+			}
+			pages = append(pages, page{Title: title, File: svgName})
+		}
+	}
+
+	return writeFile(filepath.Join(dir, "index.html"), func(w io.Writer) error {
+		title := html.EscapeString(module.Get("Name"))
+		fmt.Fprintf(w, "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>%s</title></head>\n<body>\n<h1>%s</h1>\n<p>%s</p>\n",
+			title, title, html.EscapeString(fn))
+		for _, p := range pages {
+			fmt.Fprintf(w, "<h2>%s</h2>\n<img src=\"%s\" alt=\"%[1]s\">\n",
+				html.EscapeString(p.Title), html.EscapeString(p.File))
+		}
+		_, err := io.WriteString(w, "</body></html>\n")
+		return err
+	})
+}
+
+// fileName returns the name (of the form, or canvas) as a file name in the output directory:
+// the path separators are replaced, so the name cannot escape the directory.
+func fileName(name string) string {
+	name = strings.NewReplacer("/", "_", `\`, "_").Replace(name)
+	if name == "." || !filepath.IsLocal(name) {
+		name = "_" + name
+	}
+	return name
+}
+
+func writeFile(fn string, write func(io.Writer) error) error {
+	fh, err := os.Create(fn)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+	bw := bufio.NewWriter(fh)
+	if err = write(bw); err != nil {
+		return fmt.Errorf("write %q: %w", fn, err)
+	}
+	if err = bw.Flush(); err != nil {
+		return err
+	}
+	return fh.Close()
+}
+
+type canvasRenderer struct {
+	sx, sy float64
+	items  map[string][]*transform.Node
+}
+
+func (r canvasRenderer) num(n *transform.Node, k string) float64 {
+	f, _ := strconv.ParseFloat(n.Get(k), 64)
+	return f
+}
+func (r canvasRenderer) rect(n *transform.Node) (x, y, w, h float64) {
+	return r.num(n, "XPosition") * r.sx, r.num(n, "YPosition") * r.sy,
+		r.num(n, "Width") * r.sx, r.num(n, "Height") * r.sy
+}
+
+func (r canvasRenderer) render(w io.Writer, cv, tp *transform.Node) error {
+	width, height := r.num(cv, "Width")*r.sx, r.num(cv, "Height")*r.sy
+	if width <= 0 {
+		width = 640
+	}
+	if height <= 0 {
+		height = 480
+	}
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0[1]f %.0[2]f" font-family="sans-serif" font-size="11">
+<rect width="100%%" height="100%%" fill="#e0e0e0" stroke="#808080"/>
+`, width, height)
+
+	gr := cv
+	var tabName string
+	if tp != nil {
+		gr, tabName = tp, tp.Get("Name")
+		fmt.Fprintf(w, "<text x=\"4\" y=\"12\" font-weight=\"bold\">%s</text>\n",
+			html.EscapeString(firstNonEmpty(tp.Get("Label"), tabName)))
+	}
+	for _, g := range gr.Children {
+		if g.Name == "Graphics" {
+			r.graphics(w, g)
+		}
+	}
+	for _, it := range r.items[cv.Get("Name")+"/"+tabName] {
+		r.item(w, it)
+	}
+	_, err := io.WriteString(w, "</svg>\n")
+	return err
+}
+
+func (r canvasRenderer) graphics(w io.Writer, g *transform.Node) {
+	x, y, gw, gh := r.rect(g)
+	switch g.Get("GraphicsType") {
+	case "Frame":
+		fmt.Fprintf(w, "<rect x=\"%.0f\" y=\"%.0f\" width=\"%.0f\" height=\"%.0f\" fill=\"none\" stroke=\"#404040\"/>\n", x, y, gw, gh)
+		if t := g.Get("FrameTitle"); t != "" {
+			fmt.Fprintf(w, "<text x=\"%.0f\" y=\"%.0f\">%s</text>\n", x+8, y+4, html.EscapeString(t))
+		}
+	case "Text":
+		var lines []string
+		for _, seg := range g.Find("TextSegment") {
+			lines = append(lines, seg.Get("Text"))
+		}
+		for i, s := range strings.Split(transform.Text(strings.Join(lines, "")), "\n") {
+			fmt.Fprintf(w, "<text x=\"%.0f\" y=\"%.0f\">%s</text>\n", x, y+11*float64(i+1), html.EscapeString(s))
+		}
+	case "Line":
+		fmt.Fprintf(w, "<line x1=\"%.0f\" y1=\"%.0f\" x2=\"%.0f\" y2=\"%.0f\" stroke=\"#404040\"/>\n", x, y, x+gw, y+gh)
+	default:
+		if gw > 0 && gh > 0 {
+			fmt.Fprintf(w, "<rect x=\"%.0f\" y=\"%.0f\" width=\"%.0f\" height=\"%.0f\" fill=\"none\" stroke=\"#a0a0a0\"/>\n", x, y, gw, gh)
+		}
+	}
+	for _, c := range g.Children {
+		if c.Name == "Graphics" {
+			r.graphics(w, c)
+		}
+	}
+}
+
+func (r canvasRenderer) item(w io.Writer, it *transform.Node) {
+	x, y, iw, ih := r.rect(it)
+	fill := "#ffffff"
+	switch it.Get("ItemType") {
+	case "Button", "Push Button":
+		fill = "#c0c0c0"
+	case "Display Item":
+		fill = "#f0f0f0"
+	}
+	name := html.EscapeString(it.Parent.Get("Name") + "." + it.Get("Name"))
+	fmt.Fprintf(w, "<g><title>%s (%s)</title>\n", name, html.EscapeString(it.Get("ItemType")))
+	switch it.Get("ItemType") {
+	case "Radio Group":
+		for _, rb := range it.Find("RadioButton") {
+			bx, by, _, bh := r.rect(rb)
+			fmt.Fprintf(w, "<circle cx=\"%.0f\" cy=\"%.0f\" r=\"%.0f\" fill=\"#ffffff\" stroke=\"#404040\"/>\n", bx+bh/2, by+bh/2, bh/3)
+			fmt.Fprintf(w, "<text x=\"%.0f\" y=\"%.0f\">%s</text>\n", bx+bh+2, by+bh*0.75, html.EscapeString(rb.Get("Label")))
+		}
+	default:
+		if iw > 0 && ih > 0 {
+			fmt.Fprintf(w, "<rect x=\"%.0f\" y=\"%.0f\" width=\"%.0f\" height=\"%.0f\" fill=\"%s\" stroke=\"#404040\"/>\n", x, y, iw, ih, fill)
+		}
+		label := it.Get("Label")
+		if label == "" {
+			label = it.Get("Name")
+		}
+		fmt.Fprintf(w, "<text x=\"%.0f\" y=\"%.0f\" fill=\"#808080\">%s</text>\n", x+2, y+ih*0.75, html.EscapeString(label))
+	}
+	if p := it.Get("Prompt"); p != "" {
+		px, py, anchor := x-r.num(it, "PromptAttachmentOffset")*r.sx-2, y+ih*0.75, "end"
+		if it.Get("PromptAttachmentEdge") == "Top" {
+			px, py, anchor = x+r.num(it, "PromptAlignOffset")*r.sx, y-r.num(it, "PromptAttachmentOffset")*r.sy-2, "start"
+		}
+		fmt.Fprintf(w, "<text x=\"%.0f\" y=\"%.0f\" text-anchor=\"%s\">%s</text>\n", px, py, anchor, html.EscapeString(p))
+	}
+	io.WriteString(w, "</g>\n")
+}
+
+func firstNonEmpty(ss ...string) string {
+	for _, s := range ss {
+		if s != "" {
+			return s
+		}
+	}
+	return ""
+}
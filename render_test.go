@@ -0,0 +1,157 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const renderXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Module version="101020002" xmlns="http://xmlns.oracle.com/Forms">
+  <FormModule Name="EMP">
+    <Coordinate CoordinateSystem="Character" CharacterCellWidth="7" CharacterCellHeight="14"/>
+    <Block Name="EMP">
+      <Item Name="ENAME" ItemType="Text Item" CanvasName="C_MAIN" XPosition="10" YPosition="2" Width="10" Height="1"
+        Prompt="Name &amp; title" PromptAttachmentOffset="1"/>
+      <Item Name="OK" ItemType="Push Button" Label="OK" CanvasName="C_MAIN" XPosition="1" YPosition="5" Width="6" Height="1"/>
+      <Item Name="HIDDEN" ItemType="Text Item" CanvasName="C_MAIN" Visible="false"/>
+      <Item Name="STATUS" ItemType="Radio Group" CanvasName="C_TAB" TabPageName="P1">
+        <RadioButton Name="ACTIVE" Label="Active" XPosition="1" YPosition="1" Width="8" Height="1"/>
+      </Item>
+    </Block>
+    <Canvas Name="C_MAIN" CanvasType="Content" Width="80" Height="24">
+      <Graphics Name="F1" GraphicsType="Frame" FrameTitle="Employee" XPosition="0" YPosition="1" Width="40" Height="10"/>
+      <Graphics Name="T1" GraphicsType="Text" XPosition="1" YPosition="12">
+        <TextSegment Text="first&amp;#10;second"/>
+      </Graphics>
+    </Canvas>
+    <Canvas Name="C_TAB" CanvasType="Tab">
+      <TabPage Name="P1" Label="Status"/>
+      <TabPage Name="P2"/>
+    </Canvas>
+  </FormModule>
+</Module>`
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	files := writeTestForms(t, dir, map[string]string{"emp": renderXML})
+	dst := filepath.Join(dir, "out")
+	if err := renderFiles(context.Background(), nil, dst, false, files); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	for _, tc := range []struct {
+		File          string
+		Want, NotWant []string
+	}{
+		{File: "index.html", Want: []string{
+			"<title>EMP</title>", `<img src="C_MAIN.svg" alt="C_MAIN (Content)">`,
+			`<img src="C_TAB-P1.svg" alt="C_TAB / P1 (Tab)">`, `<img src="C_TAB-P2.svg"`,
+		}},
+		{File: "C_MAIN.svg",
+			Want: []string{
+				// in pixels: 80x24 cells of 7x14
+				`width="560" height="336"`,
+				`<rect x="0" y="14" width="280" height="140" fill="none" stroke="#404040"/>`, ">Employee</text>",
+				`<text x="7" y="179">first</text>`, `<text x="7" y="190">second</text>`,
+				"<title>EMP.ENAME (Text Item)</title>", `<rect x="70" y="28" width="70" height="14" fill="#ffffff"`,
+				`text-anchor="end">Name &amp; title</text>`,
+				`<rect x="7" y="70" width="42" height="14" fill="#c0c0c0"`,
+			},
+			NotWant: []string{"HIDDEN", "STATUS"},
+		},
+		{File: "C_TAB-P1.svg",
+			Want:    []string{`width="640" height="480"`, `font-weight="bold">Status</text>`, "<circle ", ">Active</text>"},
+			NotWant: []string{"ENAME"},
+		},
+		{File: "C_TAB-P2.svg",
+			Want:    []string{`font-weight="bold">P2</text>`},
+			NotWant: []string{"STATUS"},
+		},
+	} {
+		t.Run(tc.File, func(t *testing.T) {
+			b, err := os.ReadFile(filepath.Join(dst, "EMP", tc.File))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.HasSuffix(tc.File, ".svg") {
+				// the SVGs must be well-formed
+				for dec := xml.NewDecoder(bytes.NewReader(b)); ; {
+					if _, err := dec.Token(); err == io.EOF {
+						break
+					} else if err != nil {
+						t.Fatalf("%+v\n%s", err, b)
+					}
+				}
+			}
+			s := string(b)
+			for _, w := range tc.Want {
+				if !strings.Contains(s, w) {
+					t.Errorf("no %q in\n%s", w, s)
+				}
+			}
+			for _, w := range tc.NotWant {
+				if strings.Contains(s, w) {
+					t.Errorf("%q in\n%s", w, s)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderFileNames(t *testing.T) {
+	dir := t.TempDir()
+	files := writeTestForms(t, dir, map[string]string{"evil": `<Module><FormModule Name="../EVIL">
+<Canvas Name="../../C"/><Canvas Name="C_TAB" CanvasType="Tab"><TabPage Name="/P"/></Canvas>
+</FormModule></Module>`})
+	dst := filepath.Join(dir, "out")
+	if err := renderFiles(context.Background(), nil, dst, false, files); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	var got []string
+	if err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && path != files[0] {
+			got = append(got, path)
+		}
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(dst, ".._EVIL", ".._.._C.svg"),
+		filepath.Join(dst, ".._EVIL", "C_TAB-_P.svg"),
+		filepath.Join(dst, ".._EVIL", "index.html"),
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Error(d)
+	}
+
+	for in, want := range map[string]string{"": "_", ".": "_.", "..": "_..", `a\b`: "a_b", "C_MAIN": "C_MAIN"} {
+		if got := fileName(in); got != want {
+			t.Errorf("%q: got %q, wanted %q", in, got, want)
+		}
+	}
+}
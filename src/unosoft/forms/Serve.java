@@ -15,21 +15,35 @@
 
 package unosoft.forms;
 
+import java.io.BufferedInputStream;
+import java.io.BufferedOutputStream;
+import java.io.ByteArrayOutputStream;
 import java.io.File;
 import java.io.InputStream;
 import java.io.FileInputStream;
 import java.io.FileOutputStream;
 import java.io.OutputStream;
 import java.io.IOException;
+import java.util.HashMap;
 import java.util.Map;
 import java.util.List;
 import java.util.LinkedList;
 import java.util.LinkedHashMap;
-import java.net.URLDecoder;
 import java.net.InetSocketAddress;
+import java.net.ProtocolFamily;
+import java.net.SocketAddress;
+import java.net.StandardProtocolFamily;
+import java.net.URI;
+import java.net.URLDecoder;
+import java.nio.channels.Channels;
+import java.nio.channels.ServerSocketChannel;
+import java.nio.channels.SocketChannel;
 
+import com.sun.net.httpserver.Headers;
+import com.sun.net.httpserver.HttpContext;
 import com.sun.net.httpserver.HttpExchange;
 import com.sun.net.httpserver.HttpHandler;
+import com.sun.net.httpserver.HttpPrincipal;
 import com.sun.net.httpserver.HttpServer;
 
 import oracle.forms.jdapi.Jdapi;
@@ -40,18 +54,23 @@ import oracle.forms.util.xmltools.XML2Forms;
 public class Serve {
     private InetSocketAddress addr = null;
     private HttpServer server = null;
+    private HttpHandler handler = null;
 
-    public Serve(InetSocketAddress addr) throws IOException {
-        this.addr = addr;
-        this.server = HttpServer.create(addr, 10);
-
+    public Serve() {
         String formsPath = System.getProperty("forms.lib.path");
         System.err.println("forms.lib.path=" + formsPath);
 		String conn = System.getProperty("forms.db.conn");
         System.err.println("forms.db.conn=" + conn);
 		Jdapi.connectToDatabase(conn);
 
-        server.createContext("/", new ConvertHandler(formsPath));
+        this.handler = new ConvertHandler(formsPath);
+    }
+
+    public Serve(InetSocketAddress addr) throws IOException {
+        this();
+        this.addr = addr;
+        this.server = HttpServer.create(addr, 10);
+        server.createContext("/", this.handler);
         server.setExecutor(null); // creates a default executor
     }
 
@@ -60,6 +79,23 @@ public class Serve {
         if (args.length > 0) {
             addrS = args[0];
         }
+        // on SIGTERM (a cancelled conversion), release the JDAPI context (and the database connection)
+        Runtime.getRuntime().addShutdownHook(new Thread() {
+            public void run() {
+                System.err.println("shutting down");
+                Jdapi.shutdown();
+            }
+        });
+        if (addrS.startsWith("unix:")) {
+            String path = addrS.substring("unix:".length());
+            ServerSocketChannel ch = listenUnix(path);
+            if (ch != null) {
+                (new Serve()).StartUnix(ch, path);
+                return;
+            }
+            System.err.println("cannot listen on " + addrS + ", falling back to TCP");
+            addrS = "127.0.0.1:0";
+        }
         String portS = addrS;
         int i = portS.lastIndexOf(':');
         if (i >= 0) {
@@ -97,6 +133,178 @@ public class Serve {
         Jdapi.setFailLibraryLoad(false);
         Jdapi.setFailSubclassLoad(false);
         this.server.start();
+        // the readiness handshake: the bound (maybe ephemeral) address, after connecting to the database
+        InetSocketAddress bound = this.server.getAddress();
+        System.out.println("READY " + bound.getAddress().getHostAddress() + ":" + bound.getPort());
+        System.out.flush();
+    }
+
+    // listenUnix returns a channel listening on the Unix domain socket,
+    // or null if this JVM does not support them (before Java 16).
+    // Reflection keeps this compilable with the JDK of Forms.
+    static ServerSocketChannel listenUnix(String path) {
+        try {
+            SocketAddress sa = (SocketAddress) Class.forName("java.net.UnixDomainSocketAddress").
+                getMethod("of", String.class).invoke(null, path);
+            ServerSocketChannel ch = (ServerSocketChannel) ServerSocketChannel.class.
+                getMethod("open", ProtocolFamily.class).invoke(null, StandardProtocolFamily.valueOf("UNIX"));
+            ch.bind(sa);
+            return ch;
+        } catch (Exception e) {
+            System.err.println("unix:" + path + ": " + e.toString());
+            return null;
+        }
+    }
+
+    // StartUnix serves the connections one by one (as the default executor does),
+    // as HttpServer can listen only on TCP.
+    public void StartUnix(ServerSocketChannel ch, String path) throws IOException {
+        System.err.println("Start listening on unix:" + path);
+        Jdapi.setFailLibraryLoad(false);
+        Jdapi.setFailSubclassLoad(false);
+        System.out.println("READY unix:" + path);
+        System.out.flush();
+        for (;;) {
+            SocketChannel conn = ch.accept();
+            try {
+                this.handler.handle(new UnixExchange(conn));
+            } catch (Exception e) {
+                System.err.println("EXC " + e.toString());
+            } finally {
+                conn.close();
+            }
+        }
+    }
+
+    // UnixExchange is a minimal HTTP/1.1 exchange on a connection:
+    // the request body must have a Content-Length, and the connection is closed after the response.
+    static class UnixExchange extends HttpExchange {
+        private final Headers requestHeaders = new Headers();
+        private final Headers responseHeaders = new Headers();
+        private final Map<String, Object> attributes = new HashMap<String, Object>();
+        private String method, protocol;
+        private URI uri;
+        private InputStream in;
+        private OutputStream out;
+        private final OutputStream raw;
+        private int responseCode = -1;
+
+        UnixExchange(SocketChannel ch) throws IOException {
+            InputStream is = new BufferedInputStream(Channels.newInputStream(ch));
+            this.raw = new BufferedOutputStream(Channels.newOutputStream(ch));
+            this.out = this.raw;
+            String[] parts = readLine(is).split(" ");
+            if (parts.length != 3) {
+                throw new IOException("bad request line: " + String.join(" ", parts));
+            }
+            this.method = parts[0];
+            this.uri = URI.create(parts[1]);
+            this.protocol = parts[2];
+            for (String line = readLine(is); !line.isEmpty(); line = readLine(is)) {
+                int i = line.indexOf(':');
+                if (i > 0) {
+                    requestHeaders.add(line.substring(0, i).trim(), line.substring(i + 1).trim());
+                }
+            }
+            String cl = requestHeaders.getFirst("Content-Length");
+            this.in = new LimitedInputStream(is, cl == null ? 0 : Long.parseLong(cl.trim()));
+        }
+
+        static String readLine(InputStream is) throws IOException {
+            ByteArrayOutputStream buf = new ByteArrayOutputStream();
+            for (int c = is.read(); c != '\n'; c = is.read()) {
+                if (c < 0) {
+                    throw new IOException("unexpected EOF");
+                }
+                if (c != '\r') {
+                    buf.write(c);
+                }
+            }
+            return buf.toString("ISO-8859-1");
+        }
+
+        @Override public Headers getRequestHeaders() { return requestHeaders; }
+        @Override public Headers getResponseHeaders() { return responseHeaders; }
+        @Override public URI getRequestURI() { return uri; }
+        @Override public String getRequestMethod() { return method; }
+        @Override public HttpContext getHttpContext() { return null; }
+        @Override public InputStream getRequestBody() { return in; }
+        @Override public OutputStream getResponseBody() { return out; }
+        @Override public InetSocketAddress getRemoteAddress() { return null; }
+        @Override public InetSocketAddress getLocalAddress() { return null; }
+        @Override public int getResponseCode() { return responseCode; }
+        @Override public String getProtocol() { return protocol; }
+        @Override public HttpPrincipal getPrincipal() { return null; }
+        @Override public Object getAttribute(String name) { return attributes.get(name); }
+        @Override public void setAttribute(String name, Object value) { attributes.put(name, value); }
+        @Override public void setStreams(InputStream i, OutputStream o) {
+            if (i != null) { this.in = i; }
+            if (o != null) { this.out = o; }
+        }
+
+        @Override
+        public void close() {
+            try {
+                this.out.close();
+            } catch (IOException e) {
+                System.err.println("close: " + e.toString());
+            }
+        }
+
+        // sendResponseHeaders follows HttpExchange: length 0 is an arbitrary length
+        // (till the connection is closed), -1 is no body.
+        @Override
+        public void sendResponseHeaders(int code, long length) throws IOException {
+            this.responseCode = code;
+            responseHeaders.set("Connection", "close");
+            if (length != 0) {
+                responseHeaders.set("Content-Length", String.valueOf(length < 0 ? 0 : length));
+            }
+            StringBuilder sb = new StringBuilder("HTTP/1.1 ").append(code).append(code < 400 ? " OK" : " Error").append("\r\n");
+            for (Map.Entry<String, List<String>> e : responseHeaders.entrySet()) {
+                for (String v : e.getValue()) {
+                    sb.append(e.getKey()).append(": ").append(v).append("\r\n");
+                }
+            }
+            sb.append("\r\n");
+            this.raw.write(sb.toString().getBytes("ISO-8859-1"));
+            this.raw.flush();
+        }
+    }
+
+    // LimitedInputStream reads at most n bytes.
+    static class LimitedInputStream extends InputStream {
+        private final InputStream is;
+        private long n;
+
+        LimitedInputStream(InputStream is, long n) {
+            this.is = is;
+            this.n = n;
+        }
+
+        @Override
+        public int read() throws IOException {
+            if (n <= 0) {
+                return -1;
+            }
+            int c = is.read();
+            if (c >= 0) {
+                n--;
+            }
+            return c;
+        }
+
+        @Override
+        public int read(byte[] b, int off, int len) throws IOException {
+            if (n <= 0) {
+                return -1;
+            }
+            int i = is.read(b, off, (int) Math.min(len, n));
+            if (i > 0) {
+                n -= i;
+            }
+            return i;
+        }
     }
 
     class ConvertHandler implements HttpHandler {
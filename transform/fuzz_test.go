@@ -0,0 +1,209 @@
+package transform_test
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"os"
+	"testing"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+	"github.com/google/go-cmp/cmp"
+)
+
+func generate(t testing.TB, seed uint64, opts transform.GenerateOptions) []byte {
+	var buf bytes.Buffer
+	if _, err := transform.Generate(rand.New(rand.NewPCG(seed, seed)), opts).WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// paths counts the elements by path.
+func paths(t testing.TB, b []byte) map[string]int {
+	root, err := transform.ParseTree(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("output is not well-formed: %+v\n%s", err, b)
+	}
+	m := make(map[string]int)
+	root.Walk(func(n *transform.Node) error {
+		m[n.Path()]++
+		return nil
+	})
+	return m
+}
+
+func processBytes(b []byte) ([]byte, error) {
+	return processWith(&transform.FormsXMLProcessor{}, b)
+}
+
+func processWith(P *transform.FormsXMLProcessor, b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	err := P.ProcessStream(&buf, bytes.NewReader(b))
+	return buf.Bytes(), err
+}
+
+func TestGenerate(t *testing.T) {
+	for seed := range uint64(20) {
+		in := generate(t, seed, transform.DefaultGenerateOptions)
+		root, err := transform.ParseTree(bytes.NewReader(in))
+		if err != nil {
+			t.Fatalf("%d. %+v", seed, err)
+		}
+		if errs := transform.Validate(root); len(errs) == 0 {
+			// the generated module contains the 6i problems the transformation fixes
+			t.Logf("%d. generated module is valid", seed)
+		}
+		out, err := processBytes(in)
+		if err != nil {
+			t.Fatalf("%d. %+v", seed, err)
+		}
+		root, err = transform.ParseTree(bytes.NewReader(out))
+		if err != nil {
+			t.Fatalf("%d. output is not well-formed: %+v", seed, err)
+		}
+		if errs := transform.Validate(root); len(errs) != 0 {
+			t.Errorf("%d. %v", seed, errs)
+		}
+	}
+}
+
+func addSeeds(f *testing.F) {
+	for _, fn := range fixtures(f) {
+		b, err := os.ReadFile(fn)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+	for seed := range uint64(4) {
+		f.Add(generate(f, seed, transform.DefaultGenerateOptions))
+	}
+	f.Add([]byte(`<Module><FormModule Name="X"><NORMAL/></FormModule></Module>`))
+	f.Add([]byte(`<Module><FormModule><Canvas CanvasType="Stacked"/><Item ParentModule="G_LIB"/></FormModule></Module>`))
+	// no element, only a processing instruction
+	f.Add([]byte(`<?A<FormModule?>`))
+}
+
+// hasFormModule reports whether b is well-formed, with a FormModule element.
+func hasFormModule(b []byte) bool {
+	root, err := transform.ParseTree(bytes.NewReader(b))
+	if err != nil {
+		return false
+	}
+	var found bool
+	root.Walk(func(n *transform.Node) error {
+		found = found || n.Name == "FormModule"
+		return nil
+	})
+	return found
+}
+
+// FuzzProcess checks that the transformation does not panic,
+// and its output is well-formed and stable under a second transformation.
+func FuzzProcess(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, in []byte) {
+		out, err := processBytes(in)
+		if err != nil {
+			return
+		}
+		if _, err := transform.ParseTree(bytes.NewReader(out)); err != nil {
+			if hasFormModule(in) {
+				t.Fatalf("output is not well-formed: %+v\n%s", err, out)
+			}
+			return
+		}
+		out2, err := processBytes(out)
+		if err != nil {
+			t.Fatalf("second pass: %+v", err)
+		}
+		if d := cmp.Diff(paths(t, out), paths(t, out2)); d != "" {
+			t.Errorf("second pass changed the structure (-first +second):\n%s", d)
+		}
+	})
+}
+
+// FuzzGenerate checks the transformation of generated modules: the result
+// must be valid, and a second transformation must not change it.
+func FuzzGenerate(f *testing.F) {
+	f.Add(uint64(0), uint8(3), uint8(5), uint8(2), uint8(2), uint8(2))
+	f.Add(uint64(1), uint8(0), uint8(0), uint8(0), uint8(0), uint8(0))
+	f.Add(uint64(2), uint8(1), uint8(20), uint8(5), uint8(3), uint8(4))
+	f.Fuzz(func(t *testing.T, seed uint64, blocks, items, canvases, triggers, depth uint8) {
+		in := generate(t, seed, transform.GenerateOptions{
+			Blocks: int(blocks % 8), Items: int(items % 32), Canvases: int(canvases % 8),
+			Triggers: int(triggers % 8), Depth: int(depth % 5),
+		})
+		out, err := processBytes(in)
+		if err != nil {
+			t.Fatalf("%+v\n%s", err, in)
+		}
+		root, err := transform.ParseTree(bytes.NewReader(out))
+		if err != nil {
+			t.Fatalf("output is not well-formed: %+v", err)
+		}
+		if errs := transform.Validate(root); len(errs) != 0 {
+			t.Fatalf("%v\n%s", errs, in)
+		}
+		// the output is in pixels already
+		out2, err := processWith(&transform.FormsXMLProcessor{CellWidth: 1, CellHeight: 1}, out)
+		if err != nil {
+			t.Fatalf("second pass: %+v", err)
+		}
+		if d := cmp.Diff(string(out), string(out2)); d != "" {
+			t.Errorf("second pass changed the output (-first +second):\n%s", d)
+		}
+	})
+}
+
+// FuzzParseTree checks that a parsed tree survives a WriteTo / ParseTree round trip.
+func FuzzParseTree(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, in []byte) {
+		root, err := transform.ParseTree(bytes.NewReader(in))
+		if err != nil {
+			return
+		}
+		var buf bytes.Buffer
+		if _, err := root.WriteTo(&buf); err != nil {
+			return // e.g. invalid names are not encodable
+		}
+		first := buf.String()
+		root, err = transform.ParseTree(&buf)
+		if err != nil {
+			t.Fatalf("reparse: %+v\n%s", err, first)
+		}
+		buf.Reset()
+		if _, err := root.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if d := cmp.Diff(first, buf.String()); d != "" {
+			t.Errorf("round trip (-first +second):\n%s", d)
+		}
+	})
+}
+
+// FuzzParseQuery checks that ParseQuery does not panic, and a parsed query
+// can be used.
+func FuzzParseQuery(f *testing.F) {
+	for _, s := range []string{
+		"//Item", "/Module/FormModule/Block[@Name='B']/Item", "//Item[@ItemType='Text Item'][@Prompt]",
+		"//Canvas[@CanvasType!='Stacked']", "Block/*", "//Trigger[@Name^='WHEN-']", "", "[", "//[@", "//Item[@Name='",
+	} {
+		f.Add(s)
+	}
+	root, err := transform.ParseTree(bytes.NewReader(generate(f, 0, transform.DefaultGenerateOptions)))
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		q, err := transform.ParseQuery(s)
+		if err != nil {
+			return
+		}
+		q.Select(root)
+		if _, err := transform.ParseQuery(q.String()); err != nil {
+			t.Errorf("%q: reparse %q: %+v", s, q.String(), err)
+		}
+	})
+}
@@ -0,0 +1,269 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package transform
+
+import (
+	"encoding/xml"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// GenerateOptions are the size of the generated module.
+type GenerateOptions struct {
+	Blocks, Items, Canvases, Triggers int
+	// Depth is the nesting depth of the Graphics on the canvases.
+	Depth int
+}
+
+// DefaultGenerateOptions is a small module.
+var DefaultGenerateOptions = GenerateOptions{Blocks: 3, Items: 5, Canvases: 2, Triggers: 2, Depth: 2}
+
+// Generate a random, but structurally valid Forms (6i) module, using the values
+// the transformation cares about (stacked canvases, ROOT_WINDOW, ITEM_SELECT,
+// bad item types, G_LIB parents, prompt fonts...), so the transformed module
+// should pass Validate.
+func Generate(rnd *rand.Rand, opts GenerateOptions) *Node {
+	g := generator{rnd: rnd}
+	root := &Node{Name: "Module", Attr: []xml.Attr{
+		{Name: xml.Name{Local: "version"}, Value: "101020002"},
+		{Name: xml.Name{Local: "xmlns"}, Value: "http://xmlns.oracle.com/Forms"},
+	}}
+	fm := g.add(root, "FormModule", "Name", g.name("MOD"))
+	if g.maybe() {
+		fm.Set("ConsoleWindow", "ROOT_WINDOW")
+	}
+	if g.maybe() {
+		c := g.add(fm, "Coordinate", "CharacterCellWidth", g.num(5, 12), "CharacterCellHeight", g.num(10, 24))
+		if g.maybe() {
+			c.Set("CoordinateSystem", "Character")
+		} else {
+			c.Set("CoordinateSystem", "Real")
+			c.Set("RealUnit", g.pick("Pixel", "Point", "Inch", "Centimeter"))
+		}
+	}
+	for range g.rnd.IntN(3) {
+		g.add(fm, "Alert", "Name", g.pick("KERDEZ_ALERT", "UZEN_ALERT", g.name("ALERT")),
+			"AlertMessage", g.text(), "AlertStyle", g.pick("Stop", "Caution", "Note"))
+	}
+	if g.maybe() {
+		lib := g.pick("BR_PROCEDURE_LIB", g.name("LIB"))
+		g.add(fm, "AttachedLibrary", "Name", lib, "LibraryLocation", lib, "LibrarySource", "File")
+	}
+	canvases := make([]string, opts.Canvases)
+	for i := range canvases {
+		canvases[i] = g.name("C")
+	}
+	for range opts.Blocks {
+		b := g.add(fm, "Block", "Name", g.name("B"), "QueryDataSourceName", g.name("T"))
+		g.parent(b)
+		if g.maybe() {
+			b.Set("RecordVisualAttributeGroupName", g.pick("ITEM_SELECT", "SELECT", "NORMAL"))
+		}
+		for range opts.Items {
+			g.item(b, canvases)
+		}
+		for range g.rnd.IntN(opts.Triggers + 1) {
+			g.trigger(b)
+		}
+		for _, it := range b.Find("Item") {
+			if g.maybe() {
+				g.add(b, "DataSourceColumn", "DSCName", it.Get("Name"), "DSCType", g.pick("VARCHAR2", "NUMBER", "DATE"))
+			}
+		}
+	}
+	for _, name := range canvases {
+		c := g.add(fm, "Canvas", "Name", name, "Width", g.num(40, 100), "Height", g.num(10, 30))
+		g.parent(c)
+		switch g.pick("Content", "Stacked", "Tab") {
+		case "Stacked":
+			c.Set("CanvasType", "Stacked")
+			c.Set("WindowName", "ROOT_WINDOW")
+		case "Tab":
+			c.Set("CanvasType", "Tab")
+			for range 1 + g.rnd.IntN(3) {
+				g.graphics(g.add(c, "TabPage", "Name", g.name("P"), "Label", g.text()), opts.Depth)
+			}
+			continue
+		}
+		g.graphics(c, opts.Depth)
+	}
+	if g.maybe() {
+		lov := g.add(fm, "LOV", "Name", g.name("LOV"), "Title", g.text())
+		g.add(lov, "LOVColumnMapping", "Name", g.name("COL"), "DisplayWidth", g.num(1, 20), "Title", g.text())
+	}
+	for _, name := range RequiredParams {
+		if g.maybe() {
+			g.add(fm, "ModuleParameter", "Name", name, "ParameterDataType", g.pick("Char", "Number"))
+		}
+	}
+	if g.maybe() {
+		g.add(fm, "ProgramUnit", "Name", g.name("PU"), "ProgramUnitType", "Procedure",
+			"ProgramUnitText", "PROCEDURE p IS   \nBEGIN\n  MESSAGE('"+g.text()+"');   \nEND;")
+	}
+	if g.maybe() {
+		g.parent(g.add(fm, "PropertyClass", "Name", g.name("PC")))
+	}
+	if g.maybe() {
+		rg := g.add(fm, "RecordGroup", "Name", g.name("RG"), "RecordGroupType", "Query",
+			"RecordGroupQuery", "SELECT x FROM dual")
+		g.add(rg, "RecordGroupColumn", "Name", "X")
+	}
+	for range opts.Triggers {
+		g.trigger(fm)
+	}
+	for range g.rnd.IntN(4) {
+		va := g.add(fm, "VisualAttribute", "Name", g.pick("ITEM_SELECT", "NORMAL", "NORMAL_ITEM", g.name("VA")))
+		g.parent(va)
+	}
+	for range 1 + g.rnd.IntN(2) {
+		w := g.add(fm, "Window", "Name", g.pick("ROOT_WINDOW", g.name("W")),
+			"Width", g.num(40, 100), "Height", g.num(10, 30))
+		if g.maybe() {
+			w.Set("WindowStyle", g.pick("Document", "Dialog"))
+			w.Set("FontName", "Courier")
+		}
+	}
+	return root
+}
+
+type generator struct {
+	rnd *rand.Rand
+	n   int
+}
+
+// add a child with the given attributes (key, value pairs).
+// Children with a Name already used by the same kind of child are not added.
+func (g *generator) add(parent *Node, name string, attrs ...string) *Node {
+	n := &Node{Name: name}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		n.Set(attrs[i], attrs[i+1])
+	}
+	if nm := n.Get("Name"); nm != "" && parent.Child(name, nm) != nil {
+		return n // detached
+	}
+	parent.Insert(-1, n)
+	return n
+}
+
+func (g *generator) maybe() bool { return g.rnd.IntN(2) == 0 }
+
+func (g *generator) pick(ss ...string) string { return ss[g.rnd.IntN(len(ss))] }
+
+func (g *generator) num(min, max int) string { return strconv.Itoa(min + g.rnd.IntN(max-min+1)) }
+
+// name returns a unique name with the prefix.
+func (g *generator) name(prefix string) string {
+	g.n++
+	return prefix + "_" + strconv.Itoa(g.n)
+}
+
+var generatorWords = []string{"Név", "Cím", "Dátum", "Összeg", "Kérem", "válasszon", "törli?", "& Co.", "<ok>", `"idézet"`, "árvíztűrő"}
+
+func (g *generator) text() string {
+	ss := make([]string, 1+g.rnd.IntN(3))
+	for i := range ss {
+		ss[i] = g.pick(generatorWords...)
+	}
+	return strings.Join(ss, " ")
+}
+
+// parent subclasses n, sometimes.
+func (g *generator) parent(n *Node) {
+	if g.rnd.IntN(3) != 0 {
+		return
+	}
+	mod := g.pick("G_LIB", "CIM_LIB", "BR_FLIB")
+	n.Set("ParentModule", mod)
+	n.Set("ParentFilename", mod+".fmb")
+	n.Set("ParentName", n.Get("Name"))
+}
+
+func (g *generator) item(b *Node, canvases []string) {
+	typ := g.pick("Text Item", "Text Item", "Display Item", "Check Box", "List Item", "Radio Group",
+		"Push Button", "User Area", "ActiveX Control (Obsolete)")
+	it := g.add(b, "Item", "Name", g.name("I"), "ItemType", typ,
+		"XPosition", g.num(0, 80), "YPosition", g.num(0, 25), "Width", g.num(1, 30), "Height", g.num(1, 2))
+	g.parent(it)
+	if len(canvases) != 0 {
+		it.Set("CanvasName", g.pick(canvases...))
+	}
+	if g.maybe() {
+		it.Set("Prompt", g.text())
+		if g.maybe() {
+			it.Set("PromptVisualAttributeName", g.pick("DEFAULT", "NORMAL_PROMPT", g.name("VA")))
+		}
+		for _, k := range RemovePromptVAs {
+			if g.rnd.IntN(4) == 0 {
+				it.Set(k, "Arial")
+			}
+		}
+	}
+	if g.maybe() {
+		it.Set("VisualAttributeName", g.pick("NORMAL_ITEM", g.name("VA")))
+		for _, k := range RemoveVAs {
+			if g.rnd.IntN(4) == 0 {
+				it.Set(k, "900")
+			}
+		}
+	}
+	if g.maybe() {
+		it.Set("VisualAttributeGroupName", g.pick("ITEM_SELECT", "NORMAL_ITEM"))
+	}
+	switch typ {
+	case "List Item":
+		for i := range 1 + g.rnd.IntN(3) {
+			g.add(it, "ListItemElement", "Name", g.text(), "Value", strconv.Itoa(i))
+		}
+	case "Radio Group":
+		for range 1 + g.rnd.IntN(3) {
+			g.add(it, "RadioButton", "Name", g.name("R"), "Label", g.text(),
+				"XPosition", g.num(0, 80), "YPosition", g.num(0, 25))
+		}
+	}
+	if g.rnd.IntN(4) == 0 {
+		g.trigger(it)
+	}
+}
+
+var generatorTriggers = []string{"WHEN-VALIDATE-ITEM", "WHEN-NEW-FORM-INSTANCE", "KEY-COMMIT", "PRE-QUERY", "ON-ERROR", "PRE-FORM"}
+
+func (g *generator) trigger(parent *Node) {
+	text := "BEGIN   \n  MESSAGE('" + g.text() + "');  \n  NULL;\nEND;"
+	if g.rnd.IntN(4) == 0 {
+		text = ""
+	}
+	g.add(parent, "Trigger", "Name", g.pick(generatorTriggers...), "TriggerText", text)
+}
+
+// graphics adds nested frames and texts to parent.
+func (g *generator) graphics(parent *Node, depth int) {
+	if depth <= 0 {
+		return
+	}
+	for range 1 + g.rnd.IntN(2) {
+		gr := g.add(parent, "Graphics", "Name", g.name("G"),
+			"XPosition", g.num(0, 80), "YPosition", g.num(0, 25), "Width", g.num(1, 30), "Height", g.num(1, 10))
+		if g.maybe() {
+			gr.Set("GraphicsType", "Text")
+			g.add(g.add(gr, "CompoundText"), "TextSegment", "Text", g.text())
+			continue
+		}
+		gr.Set("GraphicsType", "Frame")
+		gr.Set("FrameTitle", g.text())
+		g.graphics(gr, depth-1)
+	}
+}
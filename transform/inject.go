@@ -0,0 +1,134 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package transform
+
+import (
+	"encoding/xml"
+	"sort"
+)
+
+// FormTriggers are the form level triggers subclassed from BR_FLIB.
+var FormTriggers = []string{
+	"ON-MESSAGE", "ON-ERROR",
+	"KEY-SCRUP", "KEY-SCRDOWN", "KEY-PREV-ITEM", "KEY-NEXT-ITEM",
+	"KEY-UP", "KEY-DOWN", "KEY-OTHERS",
+	"PRE-FORM",
+}
+
+// The injected objects are inserted into the FormModule in the order of FormModuleChildren:
+// the missing objects of a section are encoded right before the first child of a later section
+// (or the end of the FormModule), so each is inserted exactly once, and only if the module
+// does not have it already.
+//
+// This relies on the children of the FormModule being in the FormModuleChildren order, as Forms
+// writes them (and Validate checks): the objects are injected before the first child of a later
+// section, so an existing object after it, out of order, is not seen in time, and is duplicated.
+
+// injectChild is called on the start of each direct child of FormModule.
+func (P *FormsXMLProcessor) injectChild(enc *xml.Encoder, st *xml.StartElement) error {
+	k := index(FormModuleChildren, st.Name.Local)
+	if k < 0 {
+		return nil
+	}
+	if name := getAttr(st.Attr, "Name"); name != "" && P.injectable(st.Name.Local, name) {
+		if P.existing == nil {
+			P.existing = make(map[string]struct{})
+		}
+		P.existing[st.Name.Local+"/"+name] = struct{}{}
+	}
+	return P.inject(enc, k)
+}
+
+// injectable reports whether an object of the kind and name may be injected,
+// so only these are remembered: the state does not grow with the size of the module.
+func (P *FormsXMLProcessor) injectable(kind, name string) bool {
+	switch kind {
+	case "AttachedLibrary":
+		return contains(RequiredLibs, name)
+	case "ModuleParameter":
+		return contains(RequiredParams, name)
+	case "Trigger":
+		return contains(FormTriggers, name)
+	case "VisualAttribute":
+		if _, ok := P.UsedVisualAttributes[name]; ok {
+			return true
+		}
+		_, ok := P.usedVAs[name]
+		return ok
+	}
+	return false
+}
+
+// inject encodes the missing objects of the FormModule sections before the upto-th.
+func (P *FormsXMLProcessor) inject(enc *xml.Encoder, upto int) error {
+	for ; P.injected < upto; P.injected++ {
+		kind := FormModuleChildren[P.injected]
+		for _, v := range P.injections(kind) {
+			if err := enc.Encode(v); err != nil {
+				return err
+			}
+			P.Stats.Injected++
+		}
+	}
+	return nil
+}
+
+// injections returns the objects of the kind to be added to the module.
+func (P *FormsXMLProcessor) injections(kind string) []any {
+	var vv []any
+	add := func(name string, v any) {
+		if _, ok := P.existing[kind+"/"+name]; !ok {
+			vv = append(vv, v)
+		}
+	}
+	switch kind {
+	case "AttachedLibrary":
+		for _, lib := range RequiredLibs {
+			add(lib, AttachedLibrary{LibrarySource: "File", Name: lib, LibraryLocation: lib})
+		}
+	case "ModuleParameter":
+		for _, name := range RequiredParams {
+			param := RequiredParam
+			param.Name, param.ParentName = name, name
+			add(name, param)
+		}
+	case "Trigger":
+		for _, name := range FormTriggers {
+			add(name, Trigger{Name: name,
+				ParentModule: "BR_FLIB", ParentModuleType: "12",
+				ParentName: name, ParentFilename: "BR_FLIB.fmb", ParentType: "37",
+			})
+		}
+	case "VisualAttribute":
+		// the used, but non-existing VisualAttributes are subclassed from BR_FLIB
+		names := make([]string, 0, len(P.UsedVisualAttributes)+len(P.usedVAs))
+		for s := range P.UsedVisualAttributes {
+			names = append(names, s)
+		}
+		for s := range P.usedVAs {
+			if _, ok := P.UsedVisualAttributes[s]; !ok {
+				names = append(names, s)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			va := DefaultVA
+			va.Name, va.ParentName = name, name
+			add(name, va)
+		}
+	}
+	return vv
+}
@@ -0,0 +1,102 @@
+package transform_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+func TestInject(t *testing.T) {
+	const head = `<?xml version="1.0" encoding="UTF-8"?>
+<Module version="101020002" xmlns="http://xmlns.oracle.com/Forms">
+  <FormModule Name="EMP">
+`
+	const tail = `  </FormModule>
+</Module>`
+	for name, body := range map[string]string{
+		"empty":      ``,
+		"blockOnly":  `<Block Name="EMP"><Item Name="ENAME" Prompt="Név"/></Block>`,
+		"noWindow":   `<Coordinate CharacterCellWidth="7"/><Alert Name="KERDEZ_ALERT"/><Canvas Name="C1"/><LOV Name="L1"/>`,
+		"manyVAs":    `<VisualAttribute Name="A"/><VisualAttribute Name="B"/><VisualAttribute Name="ITEM_SELECT"/><VisualAttribute Name="C"/>`,
+		"windowOnly": `<Window Name="ROOT_WINDOW"/>`,
+		"existing": `<AttachedLibrary Name="BR_PROCEDURE_LIB" LibraryLocation="BR_PROCEDURE_LIB"/>
+<ModuleParameter Name="TORZSSZAM"/>
+<Trigger Name="PRE-FORM" TriggerText="null;"/>
+<VisualAttribute Name="NORMAL"/><VisualAttribute Name="SELECT12"/>
+<Window Name="W1"/>`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var P transform.FormsXMLProcessor
+			var buf strings.Builder
+			if err := P.ProcessStream(&buf, strings.NewReader(head+body+tail)); err != nil {
+				t.Fatal(err)
+			}
+			root, err := transform.ParseTree(strings.NewReader(buf.String()))
+			if err != nil {
+				t.Fatalf("%+v\n%s", err, buf.String())
+			}
+			if errs := transform.Validate(root); len(errs) != 0 {
+				t.Errorf("%v\n%s", errs, buf.String())
+			}
+			module := root.Module()
+			count := make(map[string]int)
+			for _, c := range module.Children {
+				count[c.Name+"/"+c.Get("Name")]++
+			}
+			for k, n := range count {
+				if n != 1 {
+					t.Errorf("%s: %d times", k, n)
+				}
+			}
+			for _, k := range []string{
+				"AttachedLibrary/BR_PROCEDURE_LIB", "ModuleParameter/DAZON",
+				"Trigger/PRE-FORM", "Trigger/KEY-OTHERS",
+				"VisualAttribute/NORMAL", "VisualAttribute/SELECT12",
+			} {
+				if count[k] != 1 {
+					t.Errorf("%s is missing", k)
+				}
+			}
+			if count["VisualAttribute/ITEM_SELECT"] != 0 {
+				t.Error("ITEM_SELECT remained")
+			}
+		})
+	}
+}
+
+// TestInjectReuse checks that a reused processor injects into each module.
+func TestInjectReuse(t *testing.T) {
+	var P transform.FormsXMLProcessor
+	for i, body := range []string{
+		`<Trigger Name="ON-ERROR" TriggerText="null;"/>`,
+		`<Block Name="EMP"/>`,
+		`<Trigger Name="ON-ERROR" TriggerText="null;"/>`,
+	} {
+		var buf strings.Builder
+		if err := P.ProcessStream(&buf, strings.NewReader(`<Module><FormModule Name="EMP">`+body+`</FormModule></Module>`)); err != nil {
+			t.Fatal(err)
+		}
+		root, err := transform.ParseTree(strings.NewReader(buf.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		module := root.Module()
+		for _, k := range []string{"AttachedLibrary", "Trigger", "VisualAttribute"} {
+			for _, name := range map[string][]string{
+				"AttachedLibrary": transform.RequiredLibs, "Trigger": transform.FormTriggers,
+				"VisualAttribute": transform.DefaultUsedVisualAttributes,
+			}[k] {
+				var n int
+				for _, c := range module.Children {
+					if c.Name == k && c.Get("Name") == name {
+						n++
+					}
+				}
+				if n != 1 {
+					t.Errorf("%d. %s[%s]: %d times\n%s", i, k, name, n, buf.String())
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package transform
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Patch is a list of operations, each applied to the nodes selected by its query.
+//
+// The JSON form is
+//
+//	[
+//	  {"select": "//Item[@VisualAttributeName=OLD_VA]", "set": {"Bevel": "Lowered"}},
+//	  {"select": "//Item", "delete": ["FontName", "FontSize"]},
+//	  {"select": "//Block", "insert": "<Trigger Name=\"KEY-EXIT\" TriggerText=\"exit_form;\"/>"},
+//	  {"select": "//Alert[@Name=UZEN_ALERT]", "remove": true},
+//	  {"select": "//Trigger", "replace": {"attr": "TriggerText", "regexp": "(?i)\\bgo_item\\b", "with": "GO_ITEM"}}
+//	]
+type Patch []PatchOp
+
+// PatchOp is one operation of the Patch.
+type PatchOp struct {
+	Select string            `json:"select"`
+	Set    map[string]string `json:"set,omitempty"`
+	Delete []string          `json:"delete,omitempty"`
+	// Insert is an XML fragment inserted as a child of the selected nodes (as the last child,
+	// or the first if Position is "first"), unless a child with the same element and Name already exists.
+	Insert   string         `json:"insert,omitempty"`
+	Position string         `json:"position,omitempty"`
+	Remove   bool           `json:"remove,omitempty"`
+	Replace  *PatchReplace  `json:"replace,omitempty"`
+	query    *Query         `json:"-"`
+	insert   *Node          `json:"-"`
+	re       *regexp.Regexp `json:"-"`
+}
+
+// PatchReplace replaces the Regexp matches with With in the Attr attribute (PL/SQL text, usually).
+type PatchReplace struct {
+	Attr   string `json:"attr"`
+	Regexp string `json:"regexp"`
+	With   string `json:"with"`
+}
+
+// Change is a summary of the changes an operation made.
+type Change struct {
+	Op    string
+	Query string
+	Paths []string
+}
+
+// ReadPatch reads and compiles the JSON patch document.
+func ReadPatch(r io.Reader) (Patch, error) {
+	var p Patch
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return nil, errors.Wrap(err, "decode patch")
+	}
+	return p, p.compile()
+}
+
+func (p Patch) compile() error {
+	for i := range p {
+		op := &p[i]
+		var err error
+		if op.query, err = ParseQuery(op.Select); err != nil {
+			return errors.WithMessagef(err, "%d.", i+1)
+		}
+		if op.Insert != "" {
+			if op.insert, err = ParseTree(strings.NewReader(op.Insert)); err != nil {
+				return errors.WithMessagef(err, "%d. insert", i+1)
+			}
+		}
+		if op.Replace != nil {
+			if op.Replace.Attr == "" {
+				return errors.Errorf("%d. replace: attr is required", i+1)
+			}
+			if op.re, err = regexp.Compile(op.Replace.Regexp); err != nil {
+				return errors.Wrapf(err, "%d. replace", i+1)
+			}
+		}
+		switch op.Position {
+		case "", "first", "last":
+		default:
+			return errors.Errorf("%d. unknown position %q", i+1, op.Position)
+		}
+	}
+	return nil
+}
+
+// Apply the patch to the tree, returning the changes made.
+func (p Patch) Apply(root *Node) ([]Change, error) {
+	var changes []Change
+	for i := range p {
+		op := &p[i]
+		if op.query == nil {
+			if err := p.compile(); err != nil {
+				return changes, err
+			}
+		}
+		var sets, dels, ins, rems, repls []string
+		for _, n := range op.query.Select(root) {
+			path := n.Path()
+			keys := make([]string, 0, len(op.Set))
+			for k := range op.Set {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				if v := op.Set[k]; !n.Has(k) || n.Get(k) != v {
+					n.Set(k, v)
+					sets = append(sets, path+"@"+k)
+				}
+			}
+			for _, k := range op.Delete {
+				if n.Del(k) {
+					dels = append(dels, path+"@"+k)
+				}
+			}
+			if op.re != nil {
+				if v := n.Get(op.Replace.Attr); v != "" {
+					if w := op.re.ReplaceAllString(v, op.Replace.With); w != v {
+						n.Set(op.Replace.Attr, w)
+						repls = append(repls, path+"@"+op.Replace.Attr)
+					}
+				}
+			}
+			// a named object is inserted only once
+			if op.insert != nil && (op.insert.Get("Name") == "" || n.Child(op.insert.Name, op.insert.Get("Name")) == nil) {
+				pos := -1
+				if op.Position == "first" {
+					pos = 0
+				}
+				c := op.insert.clone()
+				n.Insert(pos, c)
+				ins = append(ins, c.Path())
+			}
+			if op.Remove && n.Remove() {
+				rems = append(rems, path)
+			}
+		}
+		for _, c := range []Change{
+			{Op: "set", Paths: sets}, {Op: "delete", Paths: dels},
+			{Op: "replace", Paths: repls}, {Op: "insert", Paths: ins},
+			{Op: "remove", Paths: rems},
+		} {
+			if len(c.Paths) != 0 {
+				c.Query = op.Select
+				changes = append(changes, c)
+			}
+		}
+	}
+	return changes, nil
+}
+
+func (n *Node) clone() *Node {
+	c := &Node{Name: n.Name, Line: n.Line, Attr: append(n.Attr[:0:0], n.Attr...)}
+	for _, ch := range n.Children {
+		cc := ch.clone()
+		cc.Parent = c
+		c.Children = append(c.Children, cc)
+	}
+	return c
+}
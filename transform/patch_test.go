@@ -0,0 +1,78 @@
+package transform_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+func TestPatch(t *testing.T) {
+	root, err := transform.ParseTree(strings.NewReader(queryXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, err := transform.ReadPatch(strings.NewReader(`[
+ {"select": "//Item[@ItemType='Check Box']", "set": {"Bevel": "Lowered"}, "delete": ["Prompt"]},
+ {"select": "//Block", "insert": "<Trigger Name=\"KEY-EXIT\" TriggerText=\"exit_form;\"/>", "position": "first"},
+ {"select": "/Module/FormModule/Trigger", "remove": true},
+ {"select": "//Trigger", "replace": {"attr": "TriggerText", "regexp": "(?i)go_block", "with": "GO_BLOCK"}}
+]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := patch.Apply(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]int)
+	for _, c := range changes {
+		counts[c.Op] += len(c.Paths)
+	}
+	if want := map[string]int{"set": 2, "delete": 1, "insert": 1, "remove": 1, "replace": 1}; len(counts) != len(want) {
+		t.Errorf("got %v, wanted %v", counts, want)
+	} else {
+		for k, v := range want {
+			if counts[k] != v {
+				t.Errorf("%s: got %d, wanted %d", k, counts[k], v)
+			}
+		}
+	}
+	var buf strings.Builder
+	if _, err = root.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	if !strings.Contains(s, "GO_BLOCK") || strings.Contains(s, `TriggerText="null;"`) ||
+		strings.Index(s, "KEY-EXIT") > strings.Index(s, `Name="CB"`) {
+		t.Error(s)
+	}
+
+	// idempotent
+	if changes, err = patch.Apply(root); err != nil {
+		t.Fatal(err)
+	} else if len(changes) != 0 {
+		t.Errorf("second apply: %v", changes)
+	}
+}
+
+func TestPatchInsertUnnamed(t *testing.T) {
+	root, err := transform.ParseTree(strings.NewReader(queryXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the Block has a Trigger already, but not this (unnamed) one
+	patch, err := transform.ReadPatch(strings.NewReader(`[
+ {"select": "//Block", "insert": "<Trigger TriggerText=\"null;\"/>"}
+]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := patch.Apply(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Op != "insert" || len(changes[0].Paths) != 1 {
+		t.Errorf("got %v", changes)
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package transform
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Query is a compiled XPath-like path over the Forms object tree.
+//
+// The syntax is a list of steps, each separated by "/" (child) or "//" (descendant):
+//
+//	//Block[@Name=EMP]/Item[@ItemType="Check Box"][!@Prompt]
+//	//Trigger[@Name=WHEN-VALIDATE-ITEM][@TriggerText~"(?i)go_block"]
+//
+// A step is an element name or "*", followed by zero or more predicates, all of which must match:
+//
+//	[@Attr]        the attribute exists
+//	[!@Attr]       the attribute does not exist
+//	[@Attr=v]      equals (also !=)
+//	[@Attr~re]     matches the regular expression (also !~)
+//
+// Values may be quoted with ' or ". A query not starting with "/" is searched everywhere (as with "//").
+type Query struct {
+	src   string
+	steps []queryStep
+}
+
+type queryStep struct {
+	descendant bool
+	name       string
+	preds      []queryPred
+}
+
+type queryPred struct {
+	attr   string
+	op     string
+	value  string
+	negate bool
+	re     *regexp.Regexp
+}
+
+// ParseQuery compiles the query.
+func ParseQuery(s string) (*Query, error) {
+	q := Query{src: s}
+	rest := strings.TrimSpace(s)
+	if rest == "" {
+		return nil, errors.New("empty query")
+	}
+	if !strings.HasPrefix(rest, "/") {
+		rest = "//" + rest
+	}
+	for rest != "" {
+		var st queryStep
+		switch {
+		case strings.HasPrefix(rest, "//"):
+			st.descendant, rest = true, rest[2:]
+		case strings.HasPrefix(rest, "/"):
+			rest = rest[1:]
+		default:
+			return nil, errors.Errorf("%q: expected / at %q", s, rest)
+		}
+		i := strings.IndexAny(rest, "[/")
+		if i < 0 {
+			i = len(rest)
+		}
+		if st.name = strings.TrimSpace(rest[:i]); st.name == "" {
+			return nil, errors.Errorf("%q: missing element name at %q", s, rest)
+		}
+		rest = rest[i:]
+		for strings.HasPrefix(rest, "[") {
+			p, n, err := parsePred(rest[1:])
+			if err != nil {
+				return nil, errors.WithMessage(err, s)
+			}
+			st.preds = append(st.preds, p)
+			rest = rest[1+n:]
+		}
+		q.steps = append(q.steps, st)
+	}
+	return &q, nil
+}
+
+// parsePred parses the predicate after the opening [, returning the consumed length including the closing ].
+func parsePred(s string) (queryPred, int, error) {
+	var p queryPred
+	i := 0
+	if strings.HasPrefix(s, "!") {
+		p.negate, i = true, 1
+	}
+	if !strings.HasPrefix(s[i:], "@") {
+		return p, 0, errors.Errorf("predicate should start with @ at %q", s)
+	}
+	i++
+	j := strings.IndexAny(s[i:], "=!~]")
+	if j < 0 {
+		return p, 0, errors.Errorf("unterminated predicate at %q", s)
+	}
+	p.attr = strings.TrimSpace(s[i : i+j])
+	i += j
+	if s[i] == ']' {
+		return p, i + 1, nil
+	}
+	if p.negate {
+		return p, 0, errors.Errorf("negated predicate cannot have a value at %q", s)
+	}
+	for _, op := range []string{"!=", "!~", "=", "~"} {
+		if strings.HasPrefix(s[i:], op) {
+			p.op = op
+			i += len(op)
+			break
+		}
+	}
+	if p.op == "" {
+		return p, 0, errors.Errorf("unknown operator at %q", s[i:])
+	}
+	rest := strings.TrimLeft(s[i:], " ")
+	i = len(s) - len(rest)
+	if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+		k := strings.IndexByte(rest[1:], rest[0])
+		if k < 0 {
+			return p, 0, errors.Errorf("unterminated string at %q", rest)
+		}
+		p.value = rest[1 : 1+k]
+		i += k + 2
+		rest = strings.TrimLeft(s[i:], " ")
+		i = len(s) - len(rest)
+		if !strings.HasPrefix(rest, "]") {
+			return p, 0, errors.Errorf("expected ] at %q", rest)
+		}
+	} else {
+		k := strings.IndexByte(rest, ']')
+		if k < 0 {
+			return p, 0, errors.Errorf("unterminated predicate at %q", s)
+		}
+		p.value = strings.TrimSpace(rest[:k])
+		i += k
+	}
+	if p.op == "~" || p.op == "!~" {
+		var err error
+		if p.re, err = regexp.Compile(p.value); err != nil {
+			return p, 0, errors.Wrap(err, p.value)
+		}
+	}
+	return p, i + 1, nil
+}
+
+func (q *Query) String() string { return q.src }
+
+// Select returns the nodes under root matching the query, in document order.
+func (q *Query) Select(root *Node) []*Node {
+	ctx := []*Node{{Children: []*Node{root}}}
+	for _, st := range q.steps {
+		var next []*Node
+		seen := make(map[*Node]struct{})
+		add := func(n *Node) {
+			if _, ok := seen[n]; ok || !st.match(n) {
+				return
+			}
+			seen[n] = struct{}{}
+			next = append(next, n)
+		}
+		for _, n := range ctx {
+			for _, c := range n.Children {
+				if st.descendant {
+					c.Walk(func(d *Node) error { add(d); return nil })
+				} else {
+					add(c)
+				}
+			}
+		}
+		if ctx = next; len(ctx) == 0 {
+			break
+		}
+	}
+	return ctx
+}
+
+// Match reports whether n itself matches the last step of the query and its ancestors the previous ones.
+// It is the same as n being in the Select of its root, without walking the tree.
+func (q *Query) Match(n *Node) bool {
+	return q.matchStep(n, len(q.steps)-1)
+}
+
+// matchStep reports whether n matches the i-th step, walking up its parents for the previous steps.
+func (q *Query) matchStep(n *Node, i int) bool {
+	st := q.steps[i]
+	if !st.match(n) {
+		return false
+	}
+	if i == 0 {
+		// the first step is under the document: any node for //, the root for /
+		return st.descendant || n.Parent == nil
+	}
+	if !st.descendant {
+		return n.Parent != nil && q.matchStep(n.Parent, i-1)
+	}
+	for p := n.Parent; p != nil; p = p.Parent {
+		if q.matchStep(p, i-1) {
+			return true
+		}
+	}
+	return false
+}
+
+func (st queryStep) match(n *Node) bool {
+	if st.name != "*" && st.name != n.Name {
+		return false
+	}
+	for _, p := range st.preds {
+		if !p.match(n) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p queryPred) match(n *Node) bool {
+	i := findAttr(n.Attr, p.attr)
+	if p.op == "" {
+		return (i >= 0) != p.negate
+	}
+	var v string
+	if i >= 0 {
+		v = n.Attr[i].Value
+	}
+	switch p.op {
+	case "=":
+		return i >= 0 && v == p.value
+	case "!=":
+		return v != p.value
+	case "~":
+		return i >= 0 && p.re.MatchString(v)
+	case "!~":
+		return !p.re.MatchString(v)
+	}
+	return false
+}
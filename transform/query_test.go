@@ -0,0 +1,71 @@
+package transform_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+	"github.com/google/go-cmp/cmp"
+)
+
+const queryXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Module version="101020002" xmlns="http://xmlns.oracle.com/Forms">
+  <FormModule Name="EMP">
+    <Block Name="EMP">
+      <Item Name="CB" ItemType="Check Box"/>
+      <Item Name="CB2" ItemType="Check Box" Prompt="Ok"/>
+      <Item Name="ENAME" ItemType="Text Item" Prompt="Név"/>
+      <Trigger Name="WHEN-VALIDATE-ITEM" TriggerText="begin&#10;  go_block('X');&#10;end;"/>
+    </Block>
+    <Trigger Name="WHEN-VALIDATE-ITEM" TriggerText="null;"/>
+  </FormModule>
+</Module>`
+
+func TestQuery(t *testing.T) {
+	root, err := transform.ParseTree(strings.NewReader(queryXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		Query string
+		Want  []string
+	}{
+		{`//Item[@ItemType="Check Box"][!@Prompt]`, []string{"FormModule[EMP]/Block[EMP]/Item[CB]"}},
+		{`Item[@Prompt]`, []string{"FormModule[EMP]/Block[EMP]/Item[CB2]", "FormModule[EMP]/Block[EMP]/Item[ENAME]"}},
+		{`Trigger[@Name=WHEN-VALIDATE-ITEM][@TriggerText~'(?i)GO_BLOCK']`, []string{"FormModule[EMP]/Block[EMP]/Trigger[WHEN-VALIDATE-ITEM]"}},
+		{`/Module/FormModule/Trigger`, []string{"FormModule[EMP]/Trigger[WHEN-VALIDATE-ITEM]"}},
+		{`/Module//Block//Item[@Name=ENAME]`, []string{"FormModule[EMP]/Block[EMP]/Item[ENAME]"}},
+		{`/FormModule`, nil},
+		{`//Block/*[@ItemType!='Check Box']`, []string{"FormModule[EMP]/Block[EMP]/Item[ENAME]", "FormModule[EMP]/Block[EMP]/Trigger[WHEN-VALIDATE-ITEM]"}},
+	} {
+		q, err := transform.ParseQuery(tc.Query)
+		if err != nil {
+			t.Fatalf("%q: %+v", tc.Query, err)
+		}
+		var got []string
+		for _, n := range q.Select(root) {
+			got = append(got, n.Path())
+		}
+		if d := cmp.Diff(tc.Want, got); d != "" {
+			t.Errorf("%q: %s", tc.Query, d)
+		}
+
+		// Match agrees with Select
+		got = got[:0]
+		root.Walk(func(n *transform.Node) error {
+			if q.Match(n) {
+				got = append(got, n.Path())
+			}
+			return nil
+		})
+		if d := cmp.Diff(tc.Want, got); d != "" {
+			t.Errorf("%q: Match: %s", tc.Query, d)
+		}
+	}
+
+	for _, s := range []string{"", "//Item[Prompt]", "//Item[@Prompt", "//Item[@TriggerText~'(']"} {
+		if _, err := transform.ParseQuery(s); err == nil {
+			t.Errorf("%q: wanted error", s)
+		}
+	}
+}
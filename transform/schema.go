@@ -0,0 +1,345 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package transform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ElementSchema is the derived description of an element of the Forms XML Schema (forms.xsd).
+type ElementSchema struct {
+	// Children are the allowed child elements; if Ordered, in the order required by the schema.
+	Children []string
+	Ordered  bool
+	// Required attributes.
+	Required []string
+	// Attrs are the allowed attributes besides CommonAttrs. Empty means any attribute is allowed.
+	Attrs []string
+	// Enums are the allowed values of the attributes.
+	Enums map[string][]string
+}
+
+// CommonAttrs are allowed on every element.
+var CommonAttrs = []string{
+	"Name", "Comment", "DirtyInfo", "xmlns",
+	"ParentModule", "ParentModuleType", "ParentName", "ParentFilename", "ParentFileName", "ParentType",
+	"ParentSourceLevel1ObjectName", "ParentSourceLevel2ObjectName",
+}
+
+// FormModuleChildren is the child order of FormModule.
+var FormModuleChildren = []string{
+	"Coordinate", "Alert", "AttachedLibrary", "Block", "Canvas", "Editor", "Event",
+	"LOV", "ModuleParameter", "ObjectGroup", "PopupMenu", "ProgramUnit", "PropertyClass",
+	"RecordGroup", "Report", "Trigger", "VisualAttribute", "Window",
+}
+
+var enumBevel = []string{"Raised", "Lowered", "None", "Inset", "Outset", "Plain"}
+
+// The attribute groups shared by the visual objects of forms.xsd.
+var (
+	attrsGeometry = []string{"XPosition", "YPosition", "Width", "Height"}
+	attrsVisual   = []string{"VisualAttributeName", "BackColor", "ForegroundColor", "FillPattern",
+		"FontName", "FontSize", "FontSpacing", "FontStyle", "FontWeight", "WhiteOnBlack",
+		"CharacterModeLogicalAttribute"}
+	attrsPrompt = []string{"Prompt", "PromptAlign", "PromptAlignOffset", "PromptAttachmentEdge",
+		"PromptAttachmentOffset", "PromptBackColor", "PromptDisplayStyle", "PromptFillPattern",
+		"PromptFontName", "PromptFontSize", "PromptFontSpacing", "PromptFontStyle", "PromptFontWeight",
+		"PromptForegroundColor", "PromptJustification", "PromptReadingOrder", "PromptVisualAttributeName",
+		"PromptWhiteOnBlack"}
+	attrsScrollbar = []string{"ShowHorizontalScrollbar", "ShowVerticalScrollbar"}
+)
+
+// attrs joins the attribute groups.
+func attrs(groups ...[]string) []string {
+	var n int
+	for _, g := range groups {
+		n += len(g)
+	}
+	all := make([]string, 0, n)
+	for _, g := range groups {
+		all = append(all, g...)
+	}
+	return all
+}
+
+// Schema is the derived element/attribute/enum table of the (Forms 11g/12c) forms.xsd.
+var Schema = map[string]ElementSchema{
+	"Module": {Children: []string{"FormModule", "MenuModule", "ObjectLibrary"}},
+	"FormModule": {Children: FormModuleChildren, Ordered: true, Required: []string{"Name"},
+		Attrs: []string{"Title", "ConsoleWindow", "MenuModule", "InitialMenu", "MenuRole", "MenuSource", "MenuStyle",
+			"CursorMode", "SavepointMode", "ValidationUnit", "InteractionMode", "IsolationMode",
+			"MaximumQueryTime", "MaximumRecordsFetched", "MouseNavigationLimit", "FirstNavigationBlockName",
+			"RecordVisualAttributeGroupName", "DeferRequiredEnforcement", "RuntimeCompatibilityMode",
+			"HelpBookTitle", "Direction"},
+		Enums: map[string][]string{
+			"ValidationUnit":       {"Default", "Form", "Block", "Record", "Item"},
+			"MouseNavigationLimit": {"Form", "Block", "Record", "Item"},
+		}},
+	"Coordinate": {Attrs: []string{"CharacterCellWidth", "CharacterCellHeight", "CoordinateSystem", "RealUnit", "DefaultFontScaling"},
+		Enums: map[string][]string{
+			"CoordinateSystem": {"Character", "Real"},
+			"RealUnit":         {"Pixel", "Centimeter", "Inch", "Point", "Decipoint"},
+		}},
+	"Alert": {Required: []string{"Name"},
+		Attrs: attrs([]string{"Title", "AlertMessage", "AlertStyle", "Button1Label", "Button2Label", "Button3Label",
+			"DefaultAlertButton", "Direction"}, attrsVisual),
+		Enums: map[string][]string{"AlertStyle": {"Stop", "Caution", "Note"}}},
+	"AttachedLibrary": {Required: []string{"Name"}, Attrs: []string{"LibraryLocation", "LibrarySource"},
+		Enums: map[string][]string{"LibrarySource": {"File", "Database"}}},
+	"Block": {Required: []string{"Name"},
+		Children: []string{"Item", "Relation", "Trigger", "DataSourceArgument", "DataSourceColumn"},
+		Attrs: attrs([]string{"DatabaseBlock", "SingleRecord", "EnforcePrimaryKey", "KeyMode", "LockMode",
+			"QueryAllowed", "InsertAllowed", "UpdateAllowed", "DeleteAllowed", "UpdateChangedColumns",
+			"QueryDataSourceType", "QueryDataSourceName", "WhereClause", "OrderByClause", "OptimizerHint",
+			"DMLDataTargetType", "DMLDataTargetName", "DMLReturnValue", "DMLArraySize", "QueryArraySize",
+			"QueryAllRecords", "PrecomputeSummaries", "IncludeRefItem", "EnforceColumnSecurity",
+			"MaximumQueryTime", "MaximumRecordsFetched", "RecordsBufferedCount", "RecordsDisplayCount",
+			"InsertProcedureName", "UpdateProcedureName", "DeleteProcedureName", "LockProcedureName",
+			"NavigationStyle", "PreviousNavigationBlockName", "NextNavigationBlockName",
+			"RecordOrientation", "RecordVisualAttributeGroupName", "ReverseDirection", "Alias",
+			"ShowScrollbar", "ScrollbarCanvasName", "ScrollbarTabPageName", "ScrollbarOrientation",
+			"ScrollbarXPosition", "ScrollbarYPosition", "ScrollbarWidth", "ScrollbarLength", "Direction"},
+			attrsVisual),
+		Enums: map[string][]string{
+			"QueryDataSourceType": {"None", "Table", "Procedure", "Transactional Triggers", "FROM clause query"},
+			"DMLDataTargetType":   {"None", "Table", "Procedure", "Transactional Triggers"},
+			"NavigationStyle":     {"Same Record", "Change Record", "Change Data Block"},
+			"RecordOrientation":   {"Vertical", "Horizontal"},
+		}},
+	"Item": {Required: []string{"Name"},
+		Children: []string{"ListItemElement", "RadioButton", "Trigger", "ItemNode"},
+		Attrs: attrs([]string{"ItemType", "Enabled", "Visible", "Rendered", "Label", "AccessKey", "Hint", "AutoHint",
+			"TooltipText", "TooltipVisualAttributeGroup", "DataType", "DataLengthSemantics", "MaximumLength",
+			"QueryLength", "FormatMask", "InitializeValue", "Required", "HighestAllowedValue",
+			"LowestAllowedValue", "CopyValueFromItem", "SynchronizedItemName", "CalculationMode", "Formula",
+			"SummaryFunction", "SummarizedBlockName", "SummarizedItemName", "DatabaseItem", "ColumnName",
+			"PrimaryKey", "QueryOnly", "QueryAllowed", "InsertAllowed", "UpdateAllowed", "DeleteAllowed",
+			"UpdateOnlyIfNull", "UpdateIfNull", "UpdateCommit", "LockRecord", "CaseInsensitiveQuery",
+			"CaseRestriction", "ConcealData", "KeepCursorPosition", "AutoSkip", "MultiLine", "WrapStyle",
+			"Justification", "Direction", "ReadingOrder", "InitialKeyboardState", "KeyboardState",
+			"KeyboardNavigable", "MouseNavigate", "PreviousNavigationItemName", "NextNavigationItemName",
+			"LovName", "ValidateFromList", "LovXPosition", "LovYPosition", "EditorName", "EditorXPosition",
+			"EditorYPosition", "PopupMenuName", "CanvasName", "TabPageName", "ItemsDisplay",
+			"DistanceBetweenRecords", "Bevel", "Iconic", "IconFilename", "DefaultButton", "ListStyle",
+			"MappingOfOtherValues", "CheckedValue", "UncheckedValue", "CheckBoxOtherValues",
+			"ImplementationClass", "ImageFormat", "ImageDepth", "CompressionQuality", "DisplayQuality",
+			"Sizing", "ShowPalette", "SoundFormat", "SoundQuality", "AudioChannels", "ShowPlayButton",
+			"ShowRecordButton", "ShowRewindButton", "ShowFastForwardButton", "ShowVolumeControl",
+			"ShowTimeIndicator", "ShowSlider", "CommunicationMode", "ExecuteMode",
+			"RecordVisualAttributeGroupName", "VisualAttributeGroupName"},
+			attrsGeometry, attrsVisual, attrsPrompt, attrsScrollbar),
+		Enums: map[string][]string{
+			"ItemType": {"Text Item", "Display Item", "Check Box", "List Item", "Radio Group", "Push Button",
+				"Image", "Chart Item", "Sound", "Bean Area", "OLE Container", "ActiveX Control",
+				"Hierarchical Tree", "JavaBean Control"},
+			"DataType": {"Char", "Alpha", "Date", "Datetime", "Integer", "Long", "Number", "Money",
+				"Edate", "Jdate", "Rint", "Rmoney", "Rnumber", "Time", "Object"},
+			"Bevel":                enumBevel,
+			"ListStyle":            {"Poplist", "Tlist", "Combo Box"},
+			"PromptAttachmentEdge": {"Start", "End", "Top", "Bottom"},
+		}},
+	"ListItemElement": {Attrs: []string{"Index", "Value"}},
+	"RadioButton":     {Required: []string{"Name"}, Enums: map[string][]string{"Bevel": enumBevel}},
+	"Relation":        {Required: []string{"Name"}},
+	"DataSourceColumn": {Required: []string{"DSCName"},
+		Attrs: []string{"DSCName", "DSCType", "DSCLength", "DSCPrecision", "DSCScale", "DSCMandatory",
+			"DSCNoChildren", "DSCParentName", "Type"}},
+	"DataSourceArgument": {},
+	"Canvas": {Required: []string{"Name"}, Children: []string{"Graphics", "TabPage"},
+		Attrs: attrs([]string{"CanvasType", "WindowName", "Visible", "RaiseOnEntry", "Bevel", "PopupMenuName",
+			"ViewportXPosition", "ViewportYPosition", "ViewportWidth", "ViewportHeight",
+			"ViewportXPositionOnCanvas", "ViewportYPositionOnCanvas", "TabStyle", "TabAttachmentEdge",
+			"TabWidthStyle", "CornerStyle", "Direction"},
+			attrsGeometry, attrsVisual, attrsScrollbar),
+		Enums: map[string][]string{
+			"CanvasType": {"Content", "Stacked", "Tab", "Horizontal Toolbar", "Vertical Toolbar"},
+			"Bevel":      enumBevel,
+		}},
+	"TabPage":      {Required: []string{"Name"}, Children: []string{"Graphics"}},
+	"Graphics":     {Children: []string{"Graphics", "CompoundText", "Point"}},
+	"CompoundText": {Children: []string{"TextSegment"}},
+	"TextSegment":  {},
+	"LOV":          {Required: []string{"Name"}, Children: []string{"LOVColumnMapping"}},
+	"LOVColumnMapping": {Required: []string{"Name"},
+		Attrs: []string{"DisplayWidth", "ReturnItem", "Title"}},
+	"ModuleParameter": {Required: []string{"Name"},
+		Attrs: []string{"ParameterDataType", "ParameterInitializeValue", "MaximumLength"},
+		Enums: map[string][]string{"ParameterDataType": {"Char", "Number", "Date"}}},
+	"ProgramUnit": {Required: []string{"Name"},
+		Attrs: []string{"ProgramUnitText", "ProgramUnitType"},
+		Enums: map[string][]string{"ProgramUnitType": {"Procedure", "Function", "Package Spec", "Package Body", "Unknown"}}},
+	"PropertyClass": {Required: []string{"Name"}, Children: []string{"Trigger"}},
+	"RecordGroup": {Required: []string{"Name"}, Children: []string{"RecordGroupColumn"},
+		Enums: map[string][]string{"RecordGroupType": {"Query", "Static"}}},
+	"RecordGroupColumn": {Required: []string{"Name"}, Children: []string{"ColumnValue"}},
+	"ColumnValue":       {},
+	"Trigger": {Required: []string{"Name"},
+		Attrs: []string{"TriggerText", "TriggerStyle", "FireInEnterQueryMode", "ExecuteHierarchy",
+			"DisplayInKeyboardHelp", "KeyboardHelpText", "TriggerType"},
+		Enums: map[string][]string{"ExecuteHierarchy": {"Override", "Before", "After"}}},
+	"VisualAttribute": {Required: []string{"Name"},
+		Attrs: attrs([]string{"VisualAttributeType"}, attrsVisual),
+		Enums: map[string][]string{"VisualAttributeType": {"Common", "Prompt", "Title"}}},
+	"Window": {Required: []string{"Name"},
+		Attrs: attrs([]string{"Title", "WindowStyle", "Modal", "HideOnExit", "Visible", "PrimaryCanvas",
+			"HorizontalToolbarCanvas", "VerticalToolbarCanvas", "CloseAllowed", "MoveAllowed", "ResizeAllowed",
+			"MinimizeAllowed", "MaximizeAllowed", "MinimizedTitle", "IconFilename", "InheritMenu", "Bevel",
+			"Direction"},
+			attrsGeometry, attrsVisual, attrsScrollbar),
+		Enums: map[string][]string{"WindowStyle": {"Document", "Dialog"}, "Bevel": enumBevel}},
+	"Editor":      {Required: []string{"Name"}},
+	"Event":       {Required: []string{"Name"}},
+	"ObjectGroup": {Required: []string{"Name"}, Children: []string{"ObjectGroupChild"}},
+	"PopupMenu":   {Required: []string{"Name"}, Children: []string{"MenuItem"}},
+	"Report":      {Required: []string{"Name"}},
+}
+
+// ValidationError is a problem found by Validate.
+type ValidationError struct {
+	Line int
+	Path string
+	// Attr is the attribute the problem is about, if any.
+	Attr    string
+	Message string
+	// Warning is set for the unknown attributes and elements: the Schema is
+	// only a subset of forms.xsd, so they may be valid.
+	Warning bool
+}
+
+func (ve ValidationError) Error() string {
+	msg := ve.Path + ": " + ve.Message
+	if ve.Warning {
+		msg = "warning: " + msg
+	}
+	if ve.Line > 0 {
+		return fmt.Sprintf("line %d: %s", ve.Line, msg)
+	}
+	return msg
+}
+
+// ValidationErrors is the list of problems, as an error.
+type ValidationErrors []ValidationError
+
+// Split the problems into errors and warnings.
+func (ves ValidationErrors) Split() (errs, warnings ValidationErrors) {
+	for _, ve := range ves {
+		if ve.Warning {
+			warnings = append(warnings, ve)
+		} else {
+			errs = append(errs, ve)
+		}
+	}
+	return errs, warnings
+}
+
+func (ves ValidationErrors) Error() string {
+	kind := "warnings"
+	for _, ve := range ves {
+		if !ve.Warning {
+			kind = "errors"
+			break
+		}
+	}
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%d validation %s:", len(ves), kind)
+	for i, ve := range ves {
+		if i == 10 {
+			fmt.Fprintf(&buf, "\n\t... and %d more", len(ves)-i)
+			break
+		}
+		buf.WriteString("\n\t" + ve.Error())
+	}
+	return buf.String()
+}
+
+// Validate the tree against the Schema: unknown attributes, bad enum values,
+// unexpected children, wrong child order and missing required attributes.
+// The unknown attributes and unexpected children are warnings.
+// The problems are ordered by path, then attribute.
+func Validate(root *Node) ValidationErrors {
+	var errs ValidationErrors
+	add := func(n *Node, attr string, warning bool, format string, args ...any) {
+		errs = append(errs, ValidationError{Line: n.Line, Path: n.Path(), Attr: attr,
+			Message: fmt.Sprintf(format, args...), Warning: warning})
+	}
+	common := make(map[string]struct{}, len(CommonAttrs))
+	for _, a := range CommonAttrs {
+		common[a] = struct{}{}
+	}
+	root.Walk(func(n *Node) error {
+		es, ok := Schema[n.Name]
+		if !ok {
+			return nil
+		}
+		for _, k := range es.Required {
+			if !n.Has(k) {
+				add(n, k, false, "missing required attribute %s", k)
+			}
+		}
+		for _, a := range n.Attr {
+			k := a.Name.Local
+			if vals, ok := es.Enums[k]; ok {
+				if !contains(vals, a.Value) {
+					add(n, k, false, "bad %s value %q (allowed: %s)", k, a.Value, strings.Join(vals, ", "))
+				}
+				continue
+			}
+			if _, ok := common[k]; ok || a.Name.Space == "xmlns" || len(es.Attrs) == 0 {
+				continue
+			}
+			if !contains(es.Attrs, k) {
+				add(n, k, true, "unknown attribute %s", k)
+			}
+		}
+		if len(es.Children) != 0 {
+			last := -1
+			for _, c := range n.Children {
+				i := index(es.Children, c.Name)
+				if i < 0 {
+					add(c, "", true, "unexpected element %s in %s", c.Name, n.Name)
+					continue
+				}
+				if es.Ordered && i < last {
+					add(c, "", false, "%s should precede %s", c.Name, es.Children[last])
+				}
+				if i > last {
+					last = i
+				}
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Path != errs[j].Path {
+			return errs[i].Path < errs[j].Path
+		}
+		return errs[i].Attr < errs[j].Attr
+	})
+	return errs
+}
+
+func contains(ss []string, s string) bool { return index(ss, s) >= 0 }
+
+func index(ss []string, s string) int {
+	for i, x := range ss {
+		if x == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,47 @@
+package transform_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidate(t *testing.T) {
+	root, err := transform.ParseTree(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<Module version="101020002" xmlns="http://xmlns.oracle.com/Forms">
+  <FormModule Name="EMP">
+    <AttachedLibrary Name="G_LIB" LibraryLocation="G_LIB"/>
+    <Coordinate CharacterCellWidth="7" CharacterCellHeight="14"/>
+    <Block Name="EMP">
+      <Item Name="X" ListStyle="Menu" ItemType="User Area" DataType="Text"/>
+      <Item Name="Y" Colour="red"/>
+    </Block>
+    <Trigger TriggerText="null;"/>
+  </FormModule>
+</Module>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := transform.Validate(root)
+	var got []string
+	for _, ve := range errs {
+		got = append(got, ve.Error())
+	}
+	// ordered by path, then attribute
+	want := []string{
+		`line 7: FormModule[EMP]/Block[EMP]/Item[X]: bad DataType value "Text" (allowed: Char, Alpha, Date, Datetime, Integer, Long, Number, Money, Edate, Jdate, Rint, Rmoney, Rnumber, Time, Object)`,
+		`line 7: FormModule[EMP]/Block[EMP]/Item[X]: bad ItemType value "User Area" (allowed: Text Item, Display Item, Check Box, List Item, Radio Group, Push Button, Image, Chart Item, Sound, Bean Area, OLE Container, ActiveX Control, Hierarchical Tree, JavaBean Control)`,
+		`line 7: FormModule[EMP]/Block[EMP]/Item[X]: bad ListStyle value "Menu" (allowed: Poplist, Tlist, Combo Box)`,
+		"line 8: warning: FormModule[EMP]/Block[EMP]/Item[Y]: unknown attribute Colour",
+		"line 5: FormModule[EMP]/Coordinate: Coordinate should precede AttachedLibrary",
+		"line 10: FormModule[EMP]/Trigger: missing required attribute Name",
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Error(d)
+	}
+	if errs, warnings := errs.Split(); len(errs) != 5 || len(warnings) != 1 || warnings[0].Attr != "Colour" {
+		t.Errorf("got %v, %v", errs, warnings)
+	}
+}
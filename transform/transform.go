@@ -22,6 +22,7 @@ import (
 
 	//"log"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -41,17 +42,31 @@ var DefaultUsedVisualAttributes = []string{
 	"PROMPT_ITEM", "PROMPT_ITEM12",
 }
 
+// Stats counts the changes made by the FormsXMLProcessor.
+type Stats struct {
+	StackedCanvases int // stacked canvases collapsed into C_CONTENT
+	BadItemTypes    int // items with replaced ItemType
+	VAOverrides     int // font attributes removed in favour of the VisualAttributes
+	Injected        int // objects added from BR_FLIB
+}
+
 type FormsXMLProcessor struct {
 	CellWidth, CellHeight uint8
 	UsedVisualAttributes  map[string]struct{}
 	UnknownParents        map[string]struct{}
 
+	Stats Stats
+
 	//Module Module
 
-	missingVAs    map[string]struct{}
-	missingParams map[string]struct{}
+	// The state is bounded by the nesting depth (stack) and the injectable objects:
+	// usedVAs are the referenced VisualAttributes, existing maps the
+	// Kind/Name of the injectable FormModule children, injected is the number of
+	// FormModuleChildren sections already completed with the missing objects.
+	usedVAs  map[string]struct{}
+	existing map[string]struct{}
+	injected int
 
-	seen  []string
 	stack []string
 
 	tbdPromptVAs map[string]struct{}
@@ -77,15 +92,9 @@ func (P *FormsXMLProcessor) Process(enc *xml.Encoder, dec *xml.Decoder) error {
 			P.UsedVisualAttributes[a] = struct{}{}
 		}
 	}
-	if P.missingVAs == nil {
-		P.missingVAs = make(map[string]struct{})
-	}
-	if P.missingParams == nil {
-		P.missingParams = make(map[string]struct{})
-		for _, p := range RequiredParams {
-			P.missingParams[p] = struct{}{}
-		}
-	}
+	// the state is per module: the processor may be reused
+	P.stack, P.usedVAs = P.stack[:0], make(map[string]struct{})
+	P.existing, P.injected = nil, 0
 	/* # TODO:
 	Menu Module M_MENU
 
@@ -133,9 +142,9 @@ Loop:
 			err = P.processStartElement(enc, &st)
 			st.Attr = fixAttrs(st.Attr)
 			tok = st
-			P.seen = append(P.seen, strings.Join(P.stack, "/"))
 			if err != nil {
 				if errors.Cause(err) == errSkipElement {
+					P.stack = P.stack[:len(P.stack)-1]
 					dec.Skip()
 					continue Loop
 				}
@@ -144,6 +153,11 @@ Loop:
 		case xml.EndElement:
 			st.Name.Space = ""
 			tok = st
+			if st.Name.Local == "FormModule" {
+				if err := P.inject(enc, len(FormModuleChildren)); err != nil {
+					return err
+				}
+			}
 			P.stack = P.stack[:len(P.stack)-1]
 		case xml.CharData:
 			st = xml.CharData(bytes.TrimSpace(st))
@@ -159,52 +173,26 @@ Loop:
 var errSkipElement = errors.New("skip element")
 
 func (P *FormsXMLProcessor) processStartElement(enc *xml.Encoder, st *xml.StartElement) error {
-	if len(P.seen) != 0 && strings.HasSuffix(P.seen[len(P.seen)-1], "/FormModule") {
-		P.attachLibs(enc)
-	}
 	if va := getAttr(st.Attr, "VisualAttribute"); va != "" {
-		P.missingVAs[va] = struct{}{}
+		P.usedVAs[va] = struct{}{}
 	}
-	switch st.Name.Local {
-	case "VisualAttribute":
-		P.addTriggers(enc)
-		delete(P.missingVAs, getAttr(st.Attr, "Name"))
-		if i := findAttr(st.Attr, "Name"); i >= 0 && st.Attr[i].Value == "ITEM_SELECT" {
+	if len(P.stack) > 1 && P.stack[len(P.stack)-2] == "FormModule" {
+		if st.Name.Local == "VisualAttribute" && getAttr(st.Attr, "Name") == "ITEM_SELECT" {
 			return errSkipElement
 		}
-	case "ModuleParameter":
-		delete(P.missingParams, getAttr(st.Attr, "Name"))
-	case "Window":
-		vas := make(map[string]struct{}, len(P.UsedVisualAttributes)+len(P.missingVAs))
-		for s := range P.UsedVisualAttributes {
-			vas[s] = struct{}{}
-		}
-		for s := range P.missingVAs {
-			vas[s] = struct{}{}
-		}
-		if err := P.addMissingVAs(enc, vas); err != nil {
+		if err := P.injectChild(enc, st); err != nil {
 			return err
 		}
 	}
-	if len(P.missingParams) != 0 {
-		switch st.Name.Local {
-		case "LOV", "ProgramUnit", "PropertyClass", "RecordGroup", "VisualAttribute", "Window":
-			if err := P.addMissingParams(enc, P.missingParams); err != nil {
-				return err
-			}
-			for k := range P.missingParams {
-				delete(P.missingParams, k)
-			}
-		}
-	}
 
 	if err := P.removeExcessAlert(st); err != nil {
 		return err
 	}
+	// scale before setting the (pixel) defaults of C_CONTENT
+	P.scaleElt(st)
 	P.fixStackedCanvas(st)
 	P.fixCoordinate(st)
 
-	P.scaleElt(st)
 	P.fixParentModule(st)
 	P.subclassRootwindow(st)
 	P.fixBadItemType(st)
@@ -226,23 +214,6 @@ type Trigger struct {
 	Attributes       []xml.Attr `xml:",any,attr"`
 }
 
-func (P *FormsXMLProcessor) addTriggers(enc *xml.Encoder) error {
-	for _, nm := range []string{
-		"ON-MESSAGE", "ON-ERROR",
-		"KEY-SCRUP", "KEY-SCRDOWN", "KEY-PREV-ITEM", "KEY-NEXT-ITEM",
-		"KEY-UP", "KEY-DOWN", "KEY-OTHERS",
-		"PRE-FORM",
-	} {
-		if err := enc.Encode(Trigger{Name: nm,
-			ParentModule: "BR_FLIB", ParentModuleType: "12",
-			ParentName: nm, ParentFilename: "BR_FLIB.fmb", ParentType: "37",
-		}); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 func (P *FormsXMLProcessor) removeExcessAlert(st *xml.StartElement) error {
 	if st.Name.Local != "Alert" {
 		return nil
@@ -263,20 +234,46 @@ var stackedCanvasAttrs = map[string]string{
 	"ParentModuleType":    "12",
 }
 
+// StackedContentCanvas is the stacked canvas of the old library,
+// replaced by C_CONTENT of BR_FLIB.
+const StackedContentCanvas = "C_STCK_CONTENT"
+
+// canvasRefAttrs are the attributes referencing a canvas by name.
+var canvasRefAttrs = map[string]bool{
+	"CanvasName": true, "PrimaryCanvas": true,
+	"HorizontalToolbarCanvas": true, "VerticalToolbarCanvas": true,
+}
+
 // stacked canvas -> C_CONTENT
+//
+// C_STCK_CONTENT is renamed to C_CONTENT, with the references to it (on the items, windows),
+// the other stacked canvases keep their name, and are subclassed from C_CONTENT.
+// Forms writes the canvases after the blocks, so this goes by the name, and not the CanvasType.
 func (P *FormsXMLProcessor) fixStackedCanvas(st *xml.StartElement) {
+	for i, a := range st.Attr {
+		if a.Value == StackedContentCanvas && canvasRefAttrs[a.Name.Local] {
+			st.Attr[i].Value = stackedCanvasAttrs["ParentName"]
+		}
+	}
 	if st.Name.Local != "Canvas" {
 		return
 	}
 	name := getAttr(st.Attr, "Name")
+	if name == StackedContentCanvas {
+		name = stackedCanvasAttrs["ParentName"]
+		st.Attr = setAttr(st.Attr, "Name", name)
+	}
 	if getAttr(st.Attr, "CanvasType") == "Stacked" {
+		P.Stats.StackedCanvases++
 		st.Attr = st.Attr[:1]
 		st.Attr[0].Name = xml.Name{Local: "Name"}
 		st.Attr[0].Value = name
-		for k, v := range stackedCanvasAttrs {
-			st.Attr = append(st.Attr, xml.Attr{Name: xml.Name{Local: k}, Value: v})
+		for _, k := range sortedKeys(stackedCanvasAttrs) {
+			st.Attr = append(st.Attr, xml.Attr{Name: xml.Name{Local: k}, Value: stackedCanvasAttrs[k]})
 		}
-	} else {
+	} else if getAttr(st.Attr, "ParentName") != stackedCanvasAttrs["ParentName"] ||
+		getAttr(st.Attr, "ParentModule") != stackedCanvasAttrs["ParentModule"] {
+		// already converted canvases keep their subclass
 		for i := len(st.Attr) - 1; i >= 0; i-- {
 			a := st.Attr[i]
 			if a.Name.Local == "Name" || stackedCanvasAttrs[a.Name.Local] == "" {
@@ -323,36 +320,12 @@ func (P *FormsXMLProcessor) fixCoordinate(st *xml.StartElement) {
 	st.Attr = setAttrs(st.Attr, coordinate)
 }
 
-// a használt, de nem létező VisualAttribute-okat subclassolja a BR_FLIB-ből
-func (P *FormsXMLProcessor) addMissingVAs(enc *xml.Encoder, missing map[string]struct{}) error {
-	for name := range missing {
-		va := DefaultVA
-		va.Name, va.ParentName = name, name
-		if err := enc.Encode(va); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 var RequiredParams = []string{"TORZSSZAM", "PRG_AZON", "BAZON", "DAZON"}
 
 var RequiredParam = ModuleParameter{
 	ParentType: "13", ParentModule: "BR_FLIB", ParentFilename: "BR_FLIB.fmb", ParentModuleType: "12",
 }
 
-// beteszi a 4 kötelező paramétert
-func (P *FormsXMLProcessor) addMissingParams(enc *xml.Encoder, missing map[string]struct{}) error {
-	for name := range missing {
-		param := RequiredParam
-		param.Name, param.ParentName = name, name
-		if err := enc.Encode(param); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 func (P *FormsXMLProcessor) scaleElt(st *xml.StartElement) {
 	if st.Name.Local == "Coordinate" {
 		return // against double scale
@@ -454,17 +427,6 @@ type AttachedLibrary struct {
 	LibraryLocation string `xml:",attr"`
 }
 
-func (P *FormsXMLProcessor) attachLibs(enc *xml.Encoder) error {
-	for _, lib := range RequiredLibs {
-		if err := enc.Encode(AttachedLibrary{
-			LibrarySource: "File", Name: lib, LibraryLocation: lib},
-		); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 var BadItemType = map[string]string{
 	"Check Box":                  "Display Item",
 	"User Area":                  "Text Item",
@@ -479,11 +441,13 @@ func (P *FormsXMLProcessor) fixBadItemType(st *xml.StartElement) {
 		//log.Printf("ItemType[%d]=%q => %q", i, st.Attr[i].Value, BadItemType[st.Attr[i].Value])
 		if v := BadItemType[st.Attr[i].Value]; v != "" {
 			st.Attr[i].Value = v
+			P.Stats.BadItemTypes++
 		}
 	}
 }
 
-var rSpaces = regexp.MustCompile(`\s+&amp;#10;`)
+// rSpaces matches the trailing spaces before a line break, escaped or not.
+var rSpaces = regexp.MustCompile(`[ \t]+(\r?\n|&#10;|&amp;#10;)`)
 
 func (P *FormsXMLProcessor) trimSpaces(st *xml.StartElement) {
 	switch st.Name.Local {
@@ -492,7 +456,7 @@ func (P *FormsXMLProcessor) trimSpaces(st *xml.StartElement) {
 		return
 	}
 	if i := findAttr(st.Attr, st.Name.Local+"Text"); i >= 0 && st.Attr[i].Value != "" {
-		st.Attr[i].Value = rSpaces.ReplaceAllString(st.Attr[i].Value, "&amp;#10;")
+		st.Attr[i].Value = rSpaces.ReplaceAllString(st.Attr[i].Value, "$1")
 	}
 }
 
@@ -523,7 +487,7 @@ func (P *FormsXMLProcessor) fixVAs(st *xml.StartElement) {
 			if R.Replacement == "" {
 				st.Attr = append(st.Attr[:i], st.Attr[i+1:]...)
 			} else {
-				P.missingVAs[R.Replacement] = struct{}{}
+				P.usedVAs[R.Replacement] = struct{}{}
 				st.Attr[i].Value = R.Replacement
 			}
 		}
@@ -535,11 +499,14 @@ func (P *FormsXMLProcessor) fixVAs(st *xml.StartElement) {
 
 	case "NORMAL":
 		i := findAttr(st.Attr, "Name")
+		if i < 0 {
+			break
+		}
 		rnev := st.Attr[i].Value
 		if R, ok := VAReplace[rnev]; ok {
 			st.Attr[i].Value = R.Replacement
 			st.Attr = setAttr(st.Attr, "ParentName", R.Replacement)
-			P.missingVAs[R.Replacement] = struct{}{}
+			P.usedVAs[R.Replacement] = struct{}{}
 		}
 		if i = findAttr(st.Attr, "ParentModule"); i >= 0 && st.Attr[i].Value == "G_LIB" {
 			st.Attr = setAttrs(st.Attr, VisualAttrs)
@@ -566,8 +533,10 @@ func (P *FormsXMLProcessor) fixPromptVAs(st *xml.StartElement) {
 					P.tbdPromptVAs[k] = struct{}{}
 				}
 			}
-			delAttrs(st.Attr, P.tbdPromptVAs)
-			P.missingVAs["NORMAL_PROMPT"] = struct{}{}
+			n := len(st.Attr)
+			st.Attr = delAttrs(st.Attr, P.tbdPromptVAs)
+			P.Stats.VAOverrides += n - len(st.Attr)
+			P.usedVAs["NORMAL_PROMPT"] = struct{}{}
 		}
 	}
 	if j := findAttr(st.Attr, "VisualAttributeName"); j >= 0 {
@@ -577,7 +546,9 @@ func (P *FormsXMLProcessor) fixPromptVAs(st *xml.StartElement) {
 				P.tbdVAs[k] = struct{}{}
 			}
 		}
-		delAttrs(st.Attr, P.tbdVAs)
+		n := len(st.Attr)
+		st.Attr = delAttrs(st.Attr, P.tbdVAs)
+		P.Stats.VAOverrides += n - len(st.Attr)
 	}
 }
 
@@ -619,14 +590,24 @@ func setAttrs(attrs []xml.Attr, m map[string]string) []xml.Attr {
 			seen[a.Name.Local] = struct{}{}
 		}
 	}
-	for k, v := range m {
+	for _, k := range sortedKeys(m) {
 		if _, ok := seen[k]; ok {
 			continue
 		}
-		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: k}, Value: v})
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: k}, Value: m[k]})
 	}
 	return attrs
 }
+
+// sortedKeys returns the keys of m in order, for a deterministic output.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
 func delAttrs(attrs []xml.Attr, m map[string]struct{}) []xml.Attr {
 	for i := len(attrs) - 1; i >= 0; i-- {
 		a := attrs[i]
@@ -638,14 +619,15 @@ func delAttrs(attrs []xml.Attr, m map[string]struct{}) []xml.Attr {
 }
 
 func fixAttrs(attrs []xml.Attr) []xml.Attr {
-	seen := make(map[string]struct{}, len(attrs))
+	// an element has a few attributes: a map would cost more than the quadratic scan
 	for i := len(attrs) - 1; i >= 0; i-- {
 		nm := attrs[i].Name.Local
-		if _, ok := seen[nm]; ok {
-			attrs = append(attrs[:i], attrs[i+1:]...)
-			continue
+		for _, a := range attrs[i+1:] {
+			if a.Name.Local == nm {
+				attrs = append(attrs[:i], attrs[i+1:]...)
+				break
+			}
 		}
-		seen[nm] = struct{}{}
 	}
 	return attrs
 }
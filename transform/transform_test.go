@@ -1,58 +1,87 @@
 package transform_test
 
 import (
+	"bytes"
 	"encoding/xml"
 	"flag"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/UNO-SOFT/forms2xml/transform"
 	"github.com/google/go-cmp/cmp"
 )
 
-func TestProcess(t *testing.T) {
-	fh, err := os.Open(flag.Arg(0))
+var flagUpdate = flag.Bool("update", false, "update the golden files in testdata")
+
+// fixtures returns the testdata/*.xml files and the forms given as arguments (go test -args form.xml).
+func fixtures(t testing.TB) []string {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.xml"))
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer fh.Close()
-	var P transform.FormsXMLProcessor
-	var buf strings.Builder
-	var in strings.Builder
-	if err := P.Process(xml.NewEncoder(&buf), xml.NewDecoder(io.TeeReader(fh, &in))); err != nil {
-		t.Fatal(err)
-	}
-	outS := buf.String()
-	//t.Log(outS)
-
-	if i := strings.Index(outS, "C_STCK_CONTENT"); i >= 0 {
-		i -= 100
-		if i < 0 {
-			i = 0
-		}
-		t.Errorf("C_STCK_CONTENT remained: " + outS[i:i+200])
-	}
+	return append(files, flag.Args()...)
+}
 
-	inTokens, err := startElements(strings.NewReader(in.String()))
+func process(t testing.TB, fn string) (in, out []byte) {
+	in, err := os.ReadFile(fn)
 	if err != nil {
 		t.Fatal(err)
 	}
-	//t.Log("in:", inTokens)
-	outTokens, err := startElements(strings.NewReader(outS))
-	if err != nil {
-		t.Fatal(err)
+	var P transform.FormsXMLProcessor
+	var buf bytes.Buffer
+	if err := P.ProcessStream(&buf, bytes.NewReader(in)); err != nil {
+		t.Fatalf("%s: %+v", fn, err)
 	}
-	//t.Log("out:", outTokens)
+	return in, buf.Bytes()
+}
 
-	if diff := cmp.Diff(inTokens, outTokens); diff != "" {
-		t.Log(diff)
+func TestProcess(t *testing.T) {
+	for _, fn := range fixtures(t) {
+		t.Run(strings.TrimSuffix(filepath.Base(fn), ".xml"), func(t *testing.T) {
+			_, out := process(t, fn)
+			outS := string(out)
+			if i := strings.Index(outS, transform.StackedContentCanvas); i >= 0 {
+				i -= 100
+				if i < 0 {
+					i = 0
+				}
+				t.Errorf("C_STCK_CONTENT remained: " + outS[i:min(i+200, len(outS))])
+			}
+			if filepath.Dir(fn) != "testdata" {
+				return
+			}
+
+			out = canonical(t, out)
+			golden := strings.TrimSuffix(fn, ".xml") + ".golden"
+			if *flagUpdate {
+				if err := os.WriteFile(golden, out, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%+v (run with -update to create)", err)
+			}
+			if d := cmp.Diff(strings.Split(string(want), "\n"), strings.Split(string(out), "\n")); d != "" {
+				t.Errorf("%s mismatch (-want +got):\n%s", golden, d)
+			}
+		})
 	}
 }
 
 func TestParse(t *testing.T) {
 	flag.Parse()
+	if flag.NArg() == 0 {
+		t.Skip("no form given (go test -args form.xml)")
+	}
 	var in strings.Builder
 	fh, err := os.Open(flag.Arg(0))
 	if err != nil {
@@ -100,3 +129,153 @@ func startElements(r io.Reader) ([]string, error) {
 	}
 	return ss, nil
 }
+
+// canonical returns the XML with the attributes ordered by name, as their order is not significant.
+func canonical(t testing.TB, b []byte) []byte {
+	root, err := transform.ParseTree(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("output is not well-formed: %+v", err)
+	}
+	root.Walk(func(n *transform.Node) error {
+		sort.SliceStable(n.Attr, func(i, j int) bool { return n.Attr[i].Name.Local < n.Attr[j].Name.Local })
+		return nil
+	})
+	var buf bytes.Buffer
+	if _, err = root.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// intentionallyRemoved are the objects dropped by the transformation.
+var intentionallyRemoved = []string{"Alert[KERDEZ_ALERT]", "Alert[UZEN_ALERT]", "VisualAttribute[ITEM_SELECT]"}
+
+// renamed are the objects renamed by the transformation.
+var renamed = strings.NewReplacer("[ROOT_WINDOW]", "[W_MAIN]", "["+transform.StackedContentCanvas+"]", "[C_CONTENT]")
+
+func TestInvariants(t *testing.T) {
+	for _, fn := range fixtures(t) {
+		t.Run(strings.TrimSuffix(filepath.Base(fn), ".xml"), func(t *testing.T) {
+			in, out := process(t, fn)
+			if _, out2 := process(t, fn); !bytes.Equal(out, out2) {
+				t.Error("the output differs between runs")
+			}
+
+			// well-formed, without duplicate attributes
+			dec := xml.NewDecoder(bytes.NewReader(out))
+			for {
+				tok, err := dec.Token()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("output is not well-formed: %+v", err)
+				}
+				if st, ok := tok.(xml.StartElement); ok {
+					seen := make(map[string]struct{}, len(st.Attr))
+					for _, a := range st.Attr {
+						if _, ok := seen[a.Name.Local]; ok {
+							t.Errorf("%s: duplicate attribute %s", st.Name.Local, a.Name.Local)
+						}
+						seen[a.Name.Local] = struct{}{}
+					}
+				}
+			}
+
+			// no element dropped, except the intended ones
+			count := func(b []byte) map[string]int {
+				root, err := transform.ParseTree(bytes.NewReader(b))
+				if err != nil {
+					t.Fatal(err)
+				}
+				m := make(map[string]int)
+				root.Walk(func(n *transform.Node) error {
+					m[renamed.Replace(n.Path())]++
+					return nil
+				})
+				return m
+			}
+			inCount, outCount := count(in), count(out)
+		Paths:
+			for p, n := range inCount {
+				for _, s := range intentionallyRemoved {
+					if strings.HasSuffix(p, s) || strings.Contains(p, s+"/") {
+						continue Paths
+					}
+				}
+				if outCount[p] < n {
+					t.Errorf("%s: %d in input, %d in output", p, n, outCount[p])
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkProcess(b *testing.B) {
+	for _, blocks := range []int{10, 100, 1000} {
+		opts := transform.DefaultGenerateOptions
+		opts.Blocks, opts.Items = blocks, 20
+		// stream from a file, so the input is not on the heap
+		fn := filepath.Join(b.TempDir(), "module.xml")
+		if err := os.WriteFile(fn, generate(b, 1, opts), 0o644); err != nil {
+			b.Fatal(err)
+		}
+		fi, err := os.Stat(fn)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("blocks=%d", blocks), func(b *testing.B) {
+			process := func() {
+				fh, err := os.Open(fn)
+				if err != nil {
+					b.Fatal(err)
+				}
+				defer fh.Close()
+				var P transform.FormsXMLProcessor
+				if err := P.ProcessStream(io.Discard, fh); err != nil {
+					b.Fatal(err)
+				}
+			}
+			// the live heap while streaming
+			peak := peakHeap(process)
+			b.SetBytes(fi.Size())
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				process()
+			}
+			b.ReportMetric(float64(peak), "peak-heap-B")
+		})
+	}
+}
+
+// peakHeap returns the peak of the live heap while f runs, above the live heap before.
+// The sampler collects the garbage before each sample, so this is slow.
+func peakHeap(f func()) uint64 {
+	var ms runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&ms)
+	base, peak := ms.HeapAlloc, ms.HeapAlloc
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		var ms runtime.MemStats
+		for {
+			runtime.GC()
+			runtime.ReadMemStats(&ms)
+			peak = max(peak, ms.HeapAlloc)
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	f()
+	close(done)
+	<-sampled
+	return peak - base
+}
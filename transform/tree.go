@@ -0,0 +1,313 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package transform
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Node is an element of the Forms XML object tree (Module, FormModule, Block, Item...).
+type Node struct {
+	Name     string
+	Attr     []xml.Attr
+	Children []*Node
+	Parent   *Node
+	// Line is the line number of the start element in the source, if known.
+	Line int
+}
+
+// ParseTree reads the whole Forms XML into a Node tree, returning the root (Module) element.
+func ParseTree(r io.Reader) (*Node, error) {
+	var root, cur *Node
+	line := 1
+	var buf []byte
+	dec := xml.NewDecoder(io.TeeReader(r, writerFunc(func(p []byte) (int, error) {
+		buf = append(buf, p...)
+		return len(p), nil
+	})))
+	var consumed int64
+	for {
+		if off := dec.InputOffset(); off > consumed {
+			line += bytes.Count(buf[:off-consumed], []byte{'\n'})
+			buf = buf[off-consumed:]
+			consumed = off
+		}
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return root, errors.Wrapf(err, "read line %d", line)
+		}
+		switch st := tok.(type) {
+		case xml.StartElement:
+			n := &Node{Name: st.Name.Local, Parent: cur, Line: line, Attr: make([]xml.Attr, 0, len(st.Attr))}
+			for _, a := range st.Attr {
+				if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+					if cur != nil {
+						continue
+					}
+				}
+				n.Attr = append(n.Attr, a)
+			}
+			if cur == nil {
+				if root != nil {
+					return root, errors.Errorf("second root element %q at line %d", n.Name, line)
+				}
+				root = n
+			} else {
+				cur.Children = append(cur.Children, n)
+			}
+			cur = n
+		case xml.EndElement:
+			if cur == nil {
+				return root, errors.Errorf("unexpected end element %q at line %d", st.Name.Local, line)
+			}
+			cur = cur.Parent
+		}
+	}
+	if root == nil {
+		return nil, errors.New("empty document")
+	}
+	return root, nil
+}
+
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+// Get returns the value of the named attribute, or "".
+func (n *Node) Get(name string) string { return getAttr(n.Attr, name) }
+
+// Has reports whether the named attribute exists.
+func (n *Node) Has(name string) bool { return findAttr(n.Attr, name) >= 0 }
+
+// Set sets (or appends) the named attribute.
+func (n *Node) Set(name, value string) { n.Attr = setAttr(n.Attr, name, value) }
+
+// Del deletes the named attribute, reporting whether it existed.
+func (n *Node) Del(name string) bool {
+	if i := findAttr(n.Attr, name); i >= 0 {
+		n.Attr = append(n.Attr[:i], n.Attr[i+1:]...)
+		return true
+	}
+	return false
+}
+
+// Walk calls fn for n and all its descendants, in document order.
+// If fn returns SkipChildren, the children of that node are not visited.
+func (n *Node) Walk(fn func(*Node) error) error {
+	if err := fn(n); err != nil {
+		if err == SkipChildren {
+			return nil
+		}
+		return err
+	}
+	for _, c := range n.Children {
+		if err := c.Walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SkipChildren can be returned from a Walk function to skip the node's children.
+var SkipChildren = errors.New("skip children")
+
+// Find returns the descendants of n (n included) with the given element name.
+func (n *Node) Find(name string) []*Node {
+	var nodes []*Node
+	n.Walk(func(c *Node) error {
+		if c.Name == name {
+			nodes = append(nodes, c)
+		}
+		return nil
+	})
+	return nodes
+}
+
+// Child returns the first direct child with the given element name and Name attribute.
+// An empty name matches any Name.
+func (n *Node) Child(elt, name string) *Node {
+	for _, c := range n.Children {
+		if c.Name == elt && (name == "" || c.Get("Name") == name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Module returns the FormModule (or the root, if there is no FormModule).
+func (n *Node) Module() *Node {
+	if n.Name == "Module" {
+		for _, c := range n.Children {
+			if strings.HasSuffix(c.Name, "Module") {
+				return c
+			}
+		}
+	}
+	return n
+}
+
+// Path returns the object path of the node, like FormModule[EMP]/Block[EMP]/Item[ENAME].
+func (n *Node) Path() string {
+	var parts []string
+	for c := n; c != nil && c.Name != "Module"; c = c.Parent {
+		s := c.Name
+		if nm := c.Get("Name"); nm != "" {
+			s += "[" + nm + "]"
+		}
+		parts = append(parts, s)
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, "/")
+}
+
+// Remove removes n from its parent's children.
+func (n *Node) Remove() bool {
+	if n.Parent == nil {
+		return false
+	}
+	for i, c := range n.Parent.Children {
+		if c == n {
+			n.Parent.Children = append(n.Parent.Children[:i], n.Parent.Children[i+1:]...)
+			n.Parent = nil
+			return true
+		}
+	}
+	return false
+}
+
+// Insert inserts c as the i-th child of n (appends if i is out of range).
+func (n *Node) Insert(i int, c *Node) {
+	c.Parent = n
+	if i < 0 || i >= len(n.Children) {
+		n.Children = append(n.Children, c)
+		return
+	}
+	n.Children = append(n.Children, nil)
+	copy(n.Children[i+1:], n.Children[i:])
+	n.Children[i] = c
+}
+
+// Encode the node (and its children) into enc.
+func (n *Node) Encode(enc *xml.Encoder) error {
+	st := xml.StartElement{Name: xml.Name{Local: n.Name}, Attr: n.Attr}
+	if err := enc.EncodeToken(st); err != nil {
+		return err
+	}
+	for _, c := range n.Children {
+		if err := c.Encode(enc); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(st.End())
+}
+
+// WriteTo writes the indented XML document (with header) to w.
+func (n *Node) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	if _, err := io.WriteString(cw, xml.Header); err != nil {
+		return cw.n, err
+	}
+	enc := xml.NewEncoder(cw)
+	enc.Indent("", "  ")
+	if err := n.Encode(enc); err != nil {
+		return cw.n, err
+	}
+	if err := enc.Flush(); err != nil {
+		return cw.n, err
+	}
+	_, err := io.WriteString(cw, "\n")
+	return cw.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// ProcessTree runs the transformation on the tree, returning the transformed tree.
+func (P *FormsXMLProcessor) ProcessTree(root *Node) (*Node, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		enc := xml.NewEncoder(pw)
+		err := root.Encode(enc)
+		if err == nil {
+			err = enc.Flush()
+		}
+		pw.CloseWithError(err)
+	}()
+	var buf bytes.Buffer
+	err := P.ProcessStream(&buf, pr)
+	pr.CloseWithError(err)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTree(&buf)
+}
+
+// PixelScale returns the multipliers converting the module's coordinates (see Coordinate) into pixels.
+func PixelScale(module *Node) (x, y float64) {
+	coord := module.Module().Child("Coordinate", "")
+	if coord == nil {
+		return 1, 1
+	}
+	if coord.Get("CoordinateSystem") == "Character" {
+		x, _ = strconv.ParseFloat(coord.Get("CharacterCellWidth"), 64)
+		y, _ = strconv.ParseFloat(coord.Get("CharacterCellHeight"), 64)
+		if x <= 0 {
+			x = DefaultCellWidth
+		}
+		if y <= 0 {
+			y = DefaultCellHeight
+		}
+		return x, y
+	}
+	switch coord.Get("RealUnit") {
+	case "Point":
+		return 96.0 / 72, 96.0 / 72
+	case "Inch":
+		return 96, 96
+	case "Centimeter":
+		return 96 / 2.54, 96 / 2.54
+	}
+	return 1, 1
+}
+
+// Text returns the attribute value (TriggerText, ProgramUnitText, Prompt...)
+// with the character references Forms2XML leaves in (the &#10; line breaks) resolved, once.
+func Text(s string) string {
+	if !strings.Contains(s, "&") {
+		return s
+	}
+	return html.UnescapeString(s)
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+// validateXML parses and validates the Forms XML, returning the transform.ValidationErrors, if any.
+// The warnings are only logged.
+func validateXML(r io.Reader) error {
+	root, err := transform.ParseTree(r)
+	if err != nil {
+		return err
+	}
+	errs, warnings := transform.Validate(root).Split()
+	if len(warnings) != 0 {
+		log.Println(warnings)
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateFiles validates each form (transformed first, if doTransform), printing the problems to w.
+func validateFiles(ctx context.Context, w io.Writer, converter Converter, doTransform bool, files []string) error {
+	var n int
+	for _, fn := range files {
+		root, err := readForm(ctx, converter, fn)
+		if err != nil {
+			return err
+		}
+		if doTransform {
+			var P transform.FormsXMLProcessor
+			if root, err = P.ProcessTree(root); err != nil {
+				return fmt.Errorf("transform %q: %w", fn, err)
+			}
+		}
+		for _, ve := range transform.Validate(root) {
+			severity := "error"
+			if ve.Warning {
+				severity = "warning"
+			} else {
+				n++
+			}
+			fmt.Fprintf(w, "%s:%d: %s: %s: %s\n", fn, ve.Line, severity, ve.Path, ve.Message)
+		}
+	}
+	if n != 0 {
+		return fmt.Errorf("found %d errors", n)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2025 Tamás Gulácsi
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/UNO-SOFT/forms2xml/transform"
+)
+
+func TestValidateXML(t *testing.T) {
+	const x = `<?xml version="1.0" encoding="UTF-8"?>
+<Module version="101020002" xmlns="http://xmlns.oracle.com/Forms">
+  <FormModule Name="EMP">
+    <Block Name="EMP">%s</Block>
+  </FormModule>
+</Module>`
+	// an attribute missing from the Schema does not fail the conversion
+	if err := validateXML(strings.NewReader(fmt.Sprintf(x, `<Item Name="A" NewProperty="1"/>`))); err != nil {
+		t.Errorf("unknown attribute: %+v", err)
+	}
+	err := validateXML(strings.NewReader(fmt.Sprintf(x, `<Item Name="A" NewProperty="1" ItemType="Nope"/>`)))
+	var ves transform.ValidationErrors
+	if !errors.As(err, &ves) || len(ves) != 1 || ves[0].Attr != "ItemType" {
+		t.Errorf("got %+v", err)
+	}
+}